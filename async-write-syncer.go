@@ -0,0 +1,237 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy what an AsyncWriteSyncer does when its queue is full
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	// Block the writer until there is room
+	Block OverflowPolicy = iota
+	// DropNewest discard the entry being written
+	DropNewest
+	// DropOldest discard the oldest queued entry
+	DropOldest
+)
+
+// DefaultQueueSize of an AsyncWriteSyncer
+const DefaultQueueSize = 1024
+
+// An AsyncOption configures an AsyncWriteSyncer.
+type AsyncOption interface {
+	apply(*AsyncWriteSyncer)
+}
+
+type asyncOptionFunc func(*AsyncWriteSyncer)
+
+func (f asyncOptionFunc) apply(a *AsyncWriteSyncer) {
+	f(a)
+}
+
+// QueueSize number of entries buffered. Default DefaultQueueSize
+func QueueSize(n int) AsyncOption {
+	return asyncOptionFunc(func(a *AsyncWriteSyncer) {
+		if n > 0 {
+			a.queue = make([][]byte, n)
+		}
+	})
+}
+
+// Overflow policy when the queue is full. Default Block
+func Overflow(p OverflowPolicy) AsyncOption {
+	return asyncOptionFunc(func(a *AsyncWriteSyncer) {
+		a.policy = p
+	})
+}
+
+// DroppedEncoder encodes the line reporting dropped entries, use the logger's encoder so the line matches its format.
+// Default a JSON encoder with level, ts (RFC3339Nano) and msg keys
+func DroppedEncoder(enc zapcore.Encoder) AsyncOption {
+	return asyncOptionFunc(func(a *AsyncWriteSyncer) {
+		if enc != nil {
+			a.enc = enc
+		}
+	})
+}
+
+func defaultDroppedEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		LevelKey:       "level",
+		TimeKey:        "ts",
+		MessageKey:     "msg",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+	})
+}
+
+// AsyncWriteSyncer a zapcore.WriteSyncer which queues writes in a bounded ring buffer.
+// A background goroutine writes them to the underlying WriteSyncer.
+// When entries are dropped a line with the dropped count, encoded with DroppedEncoder, is written before the next entry.
+type AsyncWriteSyncer struct {
+	ws      zapcore.WriteSyncer
+	enc     zapcore.Encoder
+	policy  OverflowPolicy
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   [][]byte
+	head    int
+	count   int
+	dropped int
+	writing bool
+	closed  bool
+	err     error
+	done    chan struct{}
+}
+
+// NewAsyncWriteSyncer Create and start an AsyncWriteSyncer writing to ws
+func NewAsyncWriteSyncer(ws zapcore.WriteSyncer, options ...AsyncOption) *AsyncWriteSyncer {
+	a := &AsyncWriteSyncer{ws: ws, enc: defaultDroppedEncoder(), queue: make([][]byte, DefaultQueueSize), done: make(chan struct{})}
+	a.cond = sync.NewCond(&a.mu)
+	for _, o := range options {
+		o.apply(a)
+	}
+	go a.drain()
+	return a
+}
+
+// Write queues a copy of p
+func (a *AsyncWriteSyncer) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return 0, fmt.Errorf("lambdazap: write to closed AsyncWriteSyncer")
+	}
+	if a.count == len(a.queue) {
+		switch a.policy {
+		case DropNewest:
+			a.dropped++
+			a.cond.Broadcast()
+			return len(p), nil
+		case DropOldest:
+			a.queue[a.head] = nil
+			a.head = (a.head + 1) % len(a.queue)
+			a.count--
+			a.dropped++
+		default:
+			for a.count == len(a.queue) && !a.closed {
+				a.cond.Wait()
+			}
+			if a.closed {
+				return 0, fmt.Errorf("lambdazap: write to closed AsyncWriteSyncer")
+			}
+		}
+	}
+	b := make([]byte, len(p))
+	copy(b, p)
+	a.queue[(a.head+a.count)%len(a.queue)] = b
+	a.count++
+	a.cond.Broadcast()
+	return len(p), nil
+}
+
+// Sync waits until the queue is drained and syncs the underlying WriteSyncer
+func (a *AsyncWriteSyncer) Sync() error {
+	a.mu.Lock()
+	for a.count > 0 || a.dropped > 0 || a.writing {
+		a.cond.Wait()
+	}
+	err := a.err
+	a.err = nil
+	a.mu.Unlock()
+	if serr := a.ws.Sync(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Close drains the queue and stops the background goroutine
+func (a *AsyncWriteSyncer) Close() error {
+	err := a.Sync()
+	a.mu.Lock()
+	a.closed = true
+	a.cond.Broadcast()
+	a.mu.Unlock()
+	<-a.done
+	return err
+}
+
+func (a *AsyncWriteSyncer) drain() {
+	defer close(a.done)
+	batch := make([][]byte, 0, len(a.queue))
+	for {
+		a.mu.Lock()
+		for a.count == 0 && a.dropped == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		if a.closed && a.count == 0 && a.dropped == 0 {
+			a.mu.Unlock()
+			return
+		}
+		batch = batch[:0]
+		for ; a.count > 0; a.count-- {
+			batch = append(batch, a.queue[a.head])
+			a.queue[a.head] = nil
+			a.head = (a.head + 1) % len(a.queue)
+		}
+		dropped := a.dropped
+		a.dropped = 0
+		a.writing = true
+		// room in the queue for blocked writers
+		a.cond.Broadcast()
+		a.mu.Unlock()
+
+		err := a.write(dropped, batch)
+
+		a.mu.Lock()
+		if err != nil {
+			a.err = err
+		}
+		a.writing = false
+		a.cond.Broadcast()
+		a.mu.Unlock()
+	}
+}
+
+func (a *AsyncWriteSyncer) write(dropped int, batch [][]byte) error {
+	var err error
+	if dropped > 0 {
+		e := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: "lambdazap dropped log entries"}
+		buf, eerr := a.enc.EncodeEntry(e, []zapcore.Field{zap.Int("dropped", dropped)})
+		if eerr != nil {
+			err = eerr
+		} else {
+			_, err = a.ws.Write(buf.Bytes())
+			buf.Free()
+		}
+	}
+	for _, b := range batch {
+		if _, werr := a.ws.Write(b); werr != nil {
+			err = werr
+		}
+	}
+	return err
+}
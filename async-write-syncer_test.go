@@ -0,0 +1,111 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// gateWriter blocks every write until the gate is opened
+type gateWriter struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	gate chan struct{}
+}
+
+func (g *gateWriter) Write(p []byte) (int, error) {
+	<-g.gate
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gateWriter) Sync() error {
+	return nil
+}
+
+func (g *gateWriter) lines() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return strings.Split(strings.TrimSpace(g.buf.String()), "\n")
+}
+
+func TestAsyncWriteSyncer(t *testing.T) {
+	gw := &gateWriter{gate: make(chan struct{})}
+	close(gw.gate)
+	ws := NewAsyncWriteSyncer(gw)
+	defer ws.Close()
+	logger := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), ws, zap.InfoLevel))
+	for i := 0; i < 100; i++ {
+		logger.Info("test")
+	}
+	assert.NoError(t, logger.Sync())
+	assert.Len(t, gw.lines(), 100)
+}
+
+func testOverflow(t *testing.T, p OverflowPolicy, options ...AsyncOption) []string {
+	gw := &gateWriter{gate: make(chan struct{})}
+	ws := NewAsyncWriteSyncer(gw, append([]AsyncOption{QueueSize(2), Overflow(p)}, options...)...)
+	ws.Write([]byte("1\n"))
+	// wait for the drainer to pick up the first entry and block on the gate
+	for {
+		ws.mu.Lock()
+		writing := ws.writing
+		ws.mu.Unlock()
+		if writing {
+			break
+		}
+		runtime.Gosched()
+	}
+	for _, l := range []string{"2\n", "3\n", "4\n", "5\n"} {
+		ws.Write([]byte(l))
+	}
+	close(gw.gate)
+	assert.NoError(t, ws.Close())
+	return gw.lines()
+}
+
+func TestAsyncWriteSyncerDropNewest(t *testing.T) {
+	lines := testOverflow(t, DropNewest)
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, []string{"1", "2", "3"}, []string{lines[0], lines[2], lines[3]})
+		assert.Contains(t, lines[1], `"dropped":2`)
+	}
+}
+
+func TestAsyncWriteSyncerDropOldest(t *testing.T) {
+	lines := testOverflow(t, DropOldest)
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, []string{"1", "4", "5"}, []string{lines[0], lines[2], lines[3]})
+		assert.Contains(t, lines[1], `"dropped":2`)
+	}
+}
+
+func TestAsyncWriteSyncerDroppedEncoder(t *testing.T) {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = ""
+	lines := testOverflow(t, DropNewest, DroppedEncoder(zapcore.NewConsoleEncoder(cfg)))
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, "warn\tlambdazap dropped log entries\t{\"dropped\": 2}", lines[1])
+	}
+}
@@ -16,6 +16,7 @@ package lambdazap
 
 import (
 	"io/ioutil"
+	"log/slog"
 	"testing"
 
 	"go.uber.org/zap"
@@ -119,3 +120,23 @@ func BenchmarkAll(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkSlogHandler(b *testing.B) {
+	lbc, cf := getContext()
+	defer cf()
+	lc := New().WithBasic()
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionConfig().EncoderConfig), &Discarder{}, zap.DebugLevel)
+	handlers := map[string]slog.Handler{
+		"json": NewSlogHandler(slog.NewJSONHandler(ioutil.Discard, nil), lc),
+		"zap":  NewZapSlogHandler(core, lc),
+	}
+	for name, h := range handlers {
+		logger := slog.New(h).With("a", 1, "b", "two").WithGroup("g")
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.InfoContext(lbc, "test", "c", 3)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// coldStart the first invocation of this execution environment
+var coldStart struct {
+	sync.Mutex
+	requestID string
+}
+
+func isColdStart(lc *lambdacontext.LambdaContext) bool {
+	coldStart.Lock()
+	defer coldStart.Unlock()
+	if coldStart.requestID == "" {
+		coldStart.requestID = lc.AwsRequestID
+	}
+	return coldStart.requestID == lc.AwsRequestID
+}
+
+// ColdStart true for the first invocation handled by the execution environment
+var ColdStart = MustRegisterField(FieldDef{
+	Name: "coldStart",
+	Kind: UserKind,
+	Resolve: func(ctx context.Context, lc *lambdacontext.LambdaContext, key string) zapcore.Field {
+		if lc.AwsRequestID == "" {
+			return zap.Skip()
+		}
+		return zap.Bool(key, isColdStart(lc))
+	},
+})
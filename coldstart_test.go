@@ -0,0 +1,38 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColdStart(t *testing.T) {
+	coldStart.requestID = ""
+	lf := New().With(ColdStart)
+	first := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "first"})
+	second := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "second"})
+	logger, tw := getLogger()
+
+	logger.Info("first", lf.ContextValues(first)...)
+	assert.Equal(t, true, tw.value["coldStart"])
+	logger.Info("second", lf.ContextValues(second)...)
+	assert.Equal(t, false, tw.value["coldStart"])
+	logger.Info("first again", lf.ContextValues(first)...)
+	assert.Equal(t, true, tw.value["coldStart"])
+}
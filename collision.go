@@ -0,0 +1,163 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// CollisionPolicy what happens when two fields have the same key, or a field uses a reserved key
+type CollisionPolicy int
+
+// Collision policies
+const (
+	// ReportCollisions keep both fields, Validate reports the collision. The default.
+	// NewMiddleware, NewSlogHandler and lambdazaplogr.NewLogr log it, Config.Build and NewProduction return it
+	ReportCollisions CollisionPolicy = iota
+	// LastWins the field added last replaces the earlier one. A reserved key is suffixed
+	LastWins
+	// AutoSuffix the field added last is renamed key_2, key_3 ...
+	AutoSuffix
+)
+
+// DefaultReservedKeys used by zap's production and development encoders
+var DefaultReservedKeys = []string{"msg", "level", "ts", "caller", "stacktrace"}
+
+// OnCollision policy for duplicate and reserved keys. Default ReportCollisions
+func OnCollision(p CollisionPolicy) Option {
+	return optionFunc(func(lc *LambdaLogContext) {
+		lc.collisions = p
+	})
+}
+
+// ReservedKeys replace DefaultReservedKeys. See EncoderKeys
+func ReservedKeys(keys ...string) Option {
+	return optionFunc(func(lc *LambdaLogContext) {
+		lc.reserved = make(map[string]bool, len(keys))
+		for _, k := range keys {
+			lc.reserved[k] = true
+		}
+	})
+}
+
+// EncoderKeys the keys an encoder config writes itself, for ReservedKeys
+func EncoderKeys(cfg zapcore.EncoderConfig) []string {
+	keys := make([]string, 0, 6)
+	for _, k := range []string{cfg.MessageKey, cfg.LevelKey, cfg.TimeKey, cfg.NameKey, cfg.CallerKey, cfg.StacktraceKey} {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func defaultReserved() map[string]bool {
+	reserved := make(map[string]bool, len(DefaultReservedKeys))
+	for _, k := range DefaultReservedKeys {
+		reserved[k] = true
+	}
+	return reserved
+}
+
+// CollisionError lists the keys used by more than one field and the fields using a reserved key
+type CollisionError struct {
+	Duplicates []string
+	Reserved   []string
+}
+
+func (e *CollisionError) Error() string {
+	var parts []string
+	if len(e.Duplicates) > 0 {
+		parts = append(parts, "duplicate keys "+strings.Join(e.Duplicates, ", "))
+	}
+	if len(e.Reserved) > 0 {
+		parts = append(parts, "reserved keys "+strings.Join(e.Reserved, ", "))
+	}
+	return fmt.Sprintf("lambdazap: %s", strings.Join(parts, "; "))
+}
+
+// Validate reports duplicate and reserved keys as a *CollisionError. Call it once the context is built.
+func (lc *LambdaLogContext) Validate() error {
+	err := &CollisionError{}
+	seen := make(map[string]int, len(lc.entries))
+	for _, e := range lc.entries {
+		seen[e.key]++
+		if seen[e.key] == 2 {
+			err.Duplicates = append(err.Duplicates, e.key)
+		}
+		if lc.reserved[e.key] && seen[e.key] == 1 {
+			err.Reserved = append(err.Reserved, e.key)
+		}
+	}
+	if len(err.Duplicates) == 0 && len(err.Reserved) == 0 {
+		return nil
+	}
+	return err
+}
+
+// collisionMessage logged with the Validate error by the Middleware, SlogHandler and lambdazaplogr.NewLogr
+const collisionMessage = "lambdazap field key collision"
+
+// LogCollisions logs the Validate error as a warning with logger, once until more fields are added.
+// NewMiddleware and lambdazaplogr.NewLogr call it, Config.Build returns the error instead
+func (lc *LambdaLogContext) LogCollisions(logger *zap.Logger) {
+	err := lc.Validate()
+	if err == nil || !atomic.CompareAndSwapUint32(&lc.collisionsLogged, 0, 1) {
+		return
+	}
+	logger.Warn(collisionMessage, zap.Error(err))
+}
+
+func (lc *LambdaLogContext) keyIndex(key string) int {
+	for i, e := range lc.entries {
+		if e.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (lc *LambdaLogContext) suffixed(key string) string {
+	for i := 2; ; i++ {
+		k := key + "_" + strconv.Itoa(i)
+		if !lc.reserved[k] && lc.keyIndex(k) < 0 {
+			return k
+		}
+	}
+}
+
+// resolveCollision renames e or removes the entry it collides with, according to the policy
+func (lc *LambdaLogContext) resolveCollision(e entry) entry {
+	switch lc.collisions {
+	case LastWins:
+		if lc.reserved[e.key] {
+			e.key = lc.suffixed(e.key)
+		} else if i := lc.keyIndex(e.key); i >= 0 {
+			entries := append(make([]entry, 0, len(lc.entries)), lc.entries[:i]...)
+			lc.rebuild(append(entries, lc.entries[i+1:]...))
+		}
+	case AutoSuffix:
+		if lc.reserved[e.key] || lc.keyIndex(e.key) >= 0 {
+			e.key = lc.suffixed(e.key)
+		}
+	}
+	return e
+}
@@ -0,0 +1,88 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var collidingNames = map[LambdaField]string{
+	CognitoIdentityID: "requestId",
+	AppTitle:          "msg",
+}
+
+func TestCollisionReport(t *testing.T) {
+	assert.NoError(t, New().WithAll().Validate())
+	err := New(CustomNames(collidingNames)).With(AwsRequestID, CognitoIdentityID, AppTitle).WithEnv("requestId").Validate()
+	if assert.IsType(t, &CollisionError{}, err) {
+		assert.Equal(t, []string{"requestId"}, err.(*CollisionError).Duplicates)
+		assert.Equal(t, []string{"msg"}, err.(*CollisionError).Reserved)
+	}
+	err = New(ReservedKeys(EncoderKeys(zap.NewDevelopmentEncoderConfig())...)).WithCustom("M", "T").Validate()
+	assert.Equal(t, "lambdazap: reserved keys M, T", err.Error())
+}
+
+func TestCollisionLastWins(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	lf := New(CustomNames(collidingNames), OnCollision(LastWins)).With(AwsRequestID, CognitoIdentityID, AppTitle)
+	assert.NoError(t, lf.Validate())
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Equal(t, "dummyident", tw.value["requestId"])
+	assert.Equal(t, "dummytitle", tw.value["msg_2"])
+	assert.Equal(t, "test", tw.value["msg"])
+}
+
+func TestCollisionAutoSuffix(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	lf := New(CustomNames(collidingNames), OnCollision(AutoSuffix)).With(AwsRequestID, CognitoIdentityID).WithEnv("custom1").WithCustom("custom1")
+	assert.NoError(t, lf.Validate())
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Equal(t, "dummyid", tw.value["requestId"])
+	assert.Equal(t, "dummyident", tw.value["requestId_2"])
+	assert.Equal(t, "", tw.value["custom1"])
+	assert.Equal(t, "dummycustom1", tw.value["custom1_2"])
+}
+
+func TestCollisionLogged(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+	lf := New().With(AwsRequestID).WithEnv("requestId")
+	NewMiddleware(logger, lf)
+	NewMiddleware(logger, lf)
+	if assert.Equal(t, 1, logs.FilterMessage(collisionMessage).Len()) {
+		assert.Contains(t, logs.All()[0].ContextMap()["error"], "duplicate keys requestId")
+	}
+
+	lf.With(AwsRequestID)
+	NewMiddleware(logger, lf)
+	assert.Equal(t, 2, logs.FilterMessage(collisionMessage).Len())
+
+	NewMiddleware(logger, New().WithAll())
+	assert.Equal(t, 2, logs.Len())
+
+	buf := &bytes.Buffer{}
+	NewSlogHandler(slog.NewJSONHandler(buf, nil), lf)
+	assert.Contains(t, buf.String(), collisionMessage)
+}
@@ -0,0 +1,229 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v2"
+)
+
+// Environment variables read by ConfigFromEnv. Lists are comma separated
+const (
+	// EnvFields groups (basic, identity, clientApp, runtime, all) and field names, e.g. basic,coldStart,traceId.
+	// Names are those of FieldNames in any naming convention, trace_id and traceId are the same field
+	EnvFields = "LAMBDAZAP_FIELDS"
+	// EnvEnv names for WithEnv
+	EnvEnv = "LAMBDAZAP_ENV"
+	// EnvCustom names for WithCustom
+	EnvCustom = "LAMBDAZAP_CUSTOM"
+	// EnvNames field name:key pairs for CustomNames, e.g. requestId:req_id
+	EnvNames = "LAMBDAZAP_NAMES"
+	// EnvLevel a zap level, e.g. debug
+	EnvLevel = "LAMBDAZAP_LEVEL"
+	// EnvNaming asis, snake, camel, kebab or dotted
+	EnvNaming = "LAMBDAZAP_NAMING"
+	// EnvPrefix see KeyPrefix
+	EnvPrefix = "LAMBDAZAP_PREFIX"
+	// EnvOrder declaration, alphabetical or a list of keys for PriorityOrder
+	EnvOrder = "LAMBDAZAP_ORDER"
+	// EnvCollisions report, lastWins or suffix
+	EnvCollisions = "LAMBDAZAP_COLLISIONS"
+)
+
+// Config builds a LambdaLogContext declaratively. See ConfigFromEnv and LoadConfig
+type Config struct {
+	Fields     []string          `json:"fields" yaml:"fields"`
+	Env        []string          `json:"env" yaml:"env"`
+	Custom     []string          `json:"custom" yaml:"custom"`
+	Names      map[string]string `json:"names" yaml:"names"`
+	Level      string            `json:"level" yaml:"level"`
+	Naming     string            `json:"naming" yaml:"naming"`
+	Prefix     string            `json:"prefix" yaml:"prefix"`
+	Order      []string          `json:"order" yaml:"order"`
+	Collisions string            `json:"collisions" yaml:"collisions"`
+}
+
+// ConfigFromEnv read the LAMBDAZAP_ environment variables
+func ConfigFromEnv() (Config, error) {
+	c := Config{
+		Fields:     splitList(os.Getenv(EnvFields)),
+		Env:        splitList(os.Getenv(EnvEnv)),
+		Custom:     splitList(os.Getenv(EnvCustom)),
+		Level:      os.Getenv(EnvLevel),
+		Naming:     os.Getenv(EnvNaming),
+		Prefix:     os.Getenv(EnvPrefix),
+		Order:      splitList(os.Getenv(EnvOrder)),
+		Collisions: os.Getenv(EnvCollisions),
+	}
+	for _, pair := range splitList(os.Getenv(EnvNames)) {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return c, fmt.Errorf("lambdazap: %s: %q is not name:key", EnvNames, pair)
+		}
+		if c.Names == nil {
+			c.Names = make(map[string]string)
+		}
+		c.Names[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return c, nil
+}
+
+// LoadConfig from a JSON or YAML document, e.g. an embedded config file
+func LoadConfig(data []byte) (Config, error) {
+	var c Config
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return c, fmt.Errorf("lambdazap: config: %v", err)
+	}
+	return c, nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+var fieldGroups = map[string]func() []LambdaField{
+	"basic":     func() []LambdaField { return BasicFields },
+	"identity":  func() []LambdaField { return IdentityFields },
+	"clientapp": func() []LambdaField { return ClientAppFields },
+	"runtime":   func() []LambdaField { return RuntimeFields },
+	"all": func() []LambdaField {
+		all := append(append(append([]LambdaField{}, BasicFields...), IdentityFields...), ClientAppFields...)
+		return append(all, MemoryLimitInMB)
+	},
+}
+
+var namingConventions = map[string]NamingConvention{
+	"":       AsIs,
+	"asis":   AsIs,
+	"snake":  SnakeCase,
+	"camel":  CamelCase,
+	"kebab":  KebabCase,
+	"dotted": DottedCase,
+}
+
+var collisionPolicies = map[string]CollisionPolicy{
+	"":         ReportCollisions,
+	"report":   ReportCollisions,
+	"lastwins": LastWins,
+	"suffix":   AutoSuffix,
+}
+
+// Options for New. Fields, Env and Custom are not options, see Build
+func (c Config) Options() ([]Option, error) {
+	var options []Option
+	if len(c.Names) > 0 {
+		names := make(map[LambdaField]string, len(c.Names))
+		for name, key := range c.Names {
+			f, ok := LookupField(name)
+			if !ok {
+				return nil, fmt.Errorf("lambdazap: names: unknown field %q", name)
+			}
+			names[f] = key
+		}
+		options = append(options, CustomNames(names))
+	}
+	naming, ok := namingConventions[strings.ToLower(c.Naming)]
+	if !ok {
+		return nil, fmt.Errorf("lambdazap: unknown naming %q, expected asis, snake, camel, kebab or dotted", c.Naming)
+	}
+	collisions, ok := collisionPolicies[strings.ToLower(c.Collisions)]
+	if !ok {
+		return nil, fmt.Errorf("lambdazap: unknown collisions %q, expected report, lastWins or suffix", c.Collisions)
+	}
+	if c.Naming != "" {
+		options = append(options, KeyNaming(naming))
+	}
+	if c.Prefix != "" {
+		options = append(options, KeyPrefix(c.Prefix))
+	}
+	if c.Collisions != "" {
+		options = append(options, OnCollision(collisions))
+	}
+	if len(c.Order) == 1 && strings.EqualFold(c.Order[0], "alphabetical") {
+		options = append(options, FieldOrder(AlphabeticalOrder))
+	} else if len(c.Order) > 0 && !(len(c.Order) == 1 && strings.EqualFold(c.Order[0], "declaration")) {
+		options = append(options, FieldOrder(PriorityOrder(c.Order...)))
+	}
+	return options, nil
+}
+
+// LambdaFields the fields and groups of Fields
+func (c Config) LambdaFields() ([]LambdaField, error) {
+	var fields []LambdaField
+	for _, name := range c.Fields {
+		if group, ok := fieldGroups[strings.ToLower(name)]; ok {
+			fields = append(fields, group()...)
+			continue
+		}
+		f, ok := LookupField(name)
+		if !ok {
+			return nil, fmt.Errorf("lambdazap: fields: unknown field %q, expected a group (basic, identity, clientApp, runtime, all) or one of %s",
+				name, strings.Join(FieldNames(), ", "))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// ZapLevel of Level, zapcore.InfoLevel when empty
+func (c Config) ZapLevel() (zapcore.Level, error) {
+	var l zapcore.Level
+	if c.Level == "" {
+		return zapcore.InfoLevel, nil
+	}
+	if err := l.UnmarshalText([]byte(c.Level)); err != nil {
+		return l, fmt.Errorf("lambdazap: level: %v", err)
+	}
+	return l, nil
+}
+
+// Build a validated LambdaLogContext. options are applied before the config's
+func (c Config) Build(options ...Option) (*LambdaLogContext, error) {
+	configOptions, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := c.LambdaFields()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.ZapLevel(); err != nil {
+		return nil, err
+	}
+	lc := New(append(options, configOptions...)...).With(fields...).WithEnv(c.Env...).WithCustom(c.Custom...)
+	if err = lc.Validate(); err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+// NewFromEnv Build a LambdaLogContext from the LAMBDAZAP_ environment variables
+func NewFromEnv(options ...Option) (*LambdaLogContext, error) {
+	c, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return c.Build(options...)
+}
@@ -0,0 +1,131 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	vars := map[string]string{
+		EnvFields: "basic, cognitoIdentityId",
+		EnvEnv:    "SHELL",
+		EnvNames:  "requestId:req_id",
+		EnvLevel:  "debug",
+	}
+	for k, v := range vars {
+		os.Setenv(k, v)
+	}
+	defer func() {
+		for k := range vars {
+			os.Unsetenv(k)
+		}
+		reset()
+	}()
+	setStatics()
+	ctx, cf := getContext()
+	defer cf()
+
+	c, err := ConfigFromEnv()
+	assert.Nil(t, err)
+	l, err := c.ZapLevel()
+	assert.Nil(t, err)
+	assert.Equal(t, zapcore.DebugLevel, l)
+
+	lc, err := NewFromEnv()
+	assert.Nil(t, err)
+	logger, tw := getLogger()
+	logger.Info("test", lc.ContextValues(ctx)...)
+	assert.Equal(t, "dummyid", tw.value["req_id"])
+	assert.Equal(t, "dummyident", tw.value["cognitoIdentityId"])
+	assert.Equal(t, os.Getenv("SHELL"), tw.value["SHELL"])
+	assert.NotContains(t, tw.value, "requestId")
+}
+
+func TestLoadConfig(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	ctx, cf := getContext()
+	defer cf()
+
+	yml := `
+fields: [requestId, functionName]
+custom: [custom1]
+naming: snake
+prefix: aws.
+`
+	c, err := LoadConfig([]byte(yml))
+	assert.Nil(t, err)
+	lc, err := c.Build()
+	assert.Nil(t, err)
+	logger, tw := getLogger()
+	logger.Info("test", lc.ContextValues(ctx)...)
+	assert.Equal(t, "dummyid", tw.value["aws.request_id"])
+	assert.Equal(t, "dummycustom1", tw.value["aws.custom1"])
+
+	c, err = LoadConfig([]byte(`{"fields": ["all"], "order": ["alphabetical"], "collisions": "suffix"}`))
+	assert.Nil(t, err)
+	lc, err = c.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, AlphabeticalOrder, lc.order)
+	assert.Equal(t, AutoSuffix, lc.collisions)
+}
+
+func TestConfigFieldNames(t *testing.T) {
+	os.Setenv(EnvFields, "basic,coldStart,traceId")
+	defer os.Unsetenv(EnvFields)
+	c, err := ConfigFromEnv()
+	assert.Nil(t, err)
+	fields, err := c.LambdaFields()
+	assert.Nil(t, err)
+	assert.Equal(t, append(append([]LambdaField{}, BasicFields...), ColdStart, TraceID), fields)
+
+	fields, err = Config{Fields: []string{"request_id", "trace-id", "MEMORY_LIMIT_IN_MB"}}.LambdaFields()
+	assert.Nil(t, err)
+	assert.Equal(t, []LambdaField{AwsRequestID, TraceID, MemoryLimitInMB}, fields)
+}
+
+func TestConfigErrors(t *testing.T) {
+	_, err := LoadConfig([]byte(`feilds: [requestId]`))
+	assert.Error(t, err)
+
+	_, err = Config{Fields: []string{"nope"}}.Build()
+	assert.Contains(t, err.Error(), `unknown field "nope"`)
+	assert.Contains(t, err.Error(), "coldStart")
+	assert.Contains(t, err.Error(), "trace_id")
+
+	_, err = Config{Names: map[string]string{"nope": "x"}}.Build()
+	assert.Contains(t, err.Error(), `unknown field "nope"`)
+
+	_, err = Config{Naming: "pascal"}.Build()
+	assert.Contains(t, err.Error(), `unknown naming "pascal"`)
+
+	_, err = Config{Collisions: "first"}.Build()
+	assert.Contains(t, err.Error(), `unknown collisions "first"`)
+
+	_, err = Config{Level: "loud"}.Build()
+	assert.Contains(t, err.Error(), "level")
+
+	os.Setenv(EnvNames, "requestId")
+	defer os.Unsetenv(EnvNames)
+	_, err = NewFromEnv()
+	assert.Contains(t, err.Error(), "is not name:key")
+}
@@ -0,0 +1,183 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// CorrelationIDHeader propagates the correlation id between services
+const CorrelationIDHeader = "X-Correlation-Id"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID a context with an inherited correlation id. The Middleware adds one, see Correlation
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext the inherited correlation id, or the AwsRequestID of the invocation
+func CorrelationIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(correlationIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		return lc.AwsRequestID
+	}
+	return ""
+}
+
+// CorrelationID the correlation id of the invocation, see CorrelationIDFromContext and Correlation
+var CorrelationID = MustRegisterField(FieldDef{
+	Name: "correlationId",
+	Kind: UserKind,
+	Resolve: func(ctx context.Context, lc *lambdacontext.LambdaContext, key string) zapcore.Field {
+		return zap.String(key, CorrelationIDFromContext(ctx))
+	},
+})
+
+// CorrelationSource returns a value of an invocation, e.g. its correlation id, empty when it has none.
+// event is the decoded event payload, nil when it is not a JSON object
+type CorrelationSource func(ctx context.Context, event map[string]interface{}) string
+
+// DefaultCorrelationSources X-Correlation-Id and X-Request-Id headers,
+// correlationId SQS/SNS message attributes, EventBridge detail and detail metadata (see EventBridgeDetail) and ClientContext.Custom
+var DefaultCorrelationSources = []CorrelationSource{
+	HeaderSource(CorrelationIDHeader, "X-Request-Id"),
+	MessageAttributeSource(CorrelationIDAttribute),
+	EventBridgeSource(CorrelationIDAttribute),
+	EventBridgeSource(EventBridgeMetadataKey + "." + CorrelationIDAttribute),
+	ClientCustomSource(CorrelationIDAttribute),
+}
+
+// HeaderSource the first of these API Gateway or function URL headers, case insensitive
+func HeaderSource(names ...string) CorrelationSource {
+	return func(ctx context.Context, event map[string]interface{}) string {
+		headers, _ := event["headers"].(map[string]interface{})
+		multi, _ := event["multiValueHeaders"].(map[string]interface{})
+		for _, name := range names {
+			for k, v := range headers {
+				if s, ok := v.(string); ok && s != "" && strings.EqualFold(k, name) {
+					return s
+				}
+			}
+			for k, v := range multi {
+				if values, ok := v.([]interface{}); ok && len(values) > 0 && strings.EqualFold(k, name) {
+					if s, ok := values[0].(string); ok {
+						return s
+					}
+				}
+			}
+		}
+		return ""
+	}
+}
+
+// MessageAttributeSource the string message attribute name of the first SQS or SNS record
+func MessageAttributeSource(name string) CorrelationSource {
+	return func(ctx context.Context, event map[string]interface{}) string {
+		records, _ := event["Records"].([]interface{})
+		if len(records) == 0 {
+			return ""
+		}
+		record, _ := records[0].(map[string]interface{})
+		if s := lookupString(record, "messageAttributes", name, "stringValue"); s != "" {
+			return s
+		}
+		return lookupString(record, "Sns", "MessageAttributes", name, "Value")
+	}
+}
+
+// EventBridgeSource the string at the dotted path in an EventBridge event's detail, e.g. metadata.correlationId
+func EventBridgeSource(path string) CorrelationSource {
+	keys := append([]string{"detail"}, strings.Split(path, ".")...)
+	return func(ctx context.Context, event map[string]interface{}) string {
+		if _, ok := event["detail-type"]; !ok {
+			return ""
+		}
+		return lookupString(event, keys...)
+	}
+}
+
+// ClientCustomSource the lambdacontext.ClientContext.Custom value of key
+func ClientCustomSource(key string) CorrelationSource {
+	return func(ctx context.Context, event map[string]interface{}) string {
+		if lc, ok := lambdacontext.FromContext(ctx); ok {
+			return lc.ClientContext.Custom[key]
+		}
+		return ""
+	}
+}
+
+func lookupString(m map[string]interface{}, keys ...string) string {
+	for i, k := range keys {
+		if i == len(keys)-1 {
+			s, _ := m[k].(string)
+			return s
+		}
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		m = next
+	}
+	return ""
+}
+
+// NewCorrelationID a random UUID
+func NewCorrelationID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// decodeEvent the payload when it is a JSON object, nil otherwise
+func decodeEvent(payload []byte) map[string]interface{} {
+	var event map[string]interface{}
+	if json.Unmarshal(payload, &event) != nil {
+		return nil
+	}
+	return event
+}
+
+// firstSource the value of the first source with one
+func firstSource(ctx context.Context, event map[string]interface{}, sources []CorrelationSource) string {
+	for _, source := range sources {
+		if v := source(ctx, event); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// resolveCorrelationID from the first source with one, generated when there is none
+func resolveCorrelationID(ctx context.Context, event map[string]interface{}, sources []CorrelationSource, generate func() string) string {
+	if id := firstSource(ctx, event, sources); id != "" {
+		return id
+	}
+	return generate()
+}
@@ -0,0 +1,77 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCorrelationSources(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), lc)
+	never := func() string { return "generated" }
+	for event, expected := range map[string]string{
+		`{"headers": {"x-correlation-id": "header"}}`:                                                           "header",
+		`{"multiValueHeaders": {"X-Request-Id": ["multi"]}}`:                                                    "multi",
+		`{"Records": [{"messageAttributes": {"correlationId": {"stringValue": "sqs", "dataType": "String"}}}]}`: "sqs",
+		`{"Records": [{"Sns": {"MessageAttributes": {"correlationId": {"Type": "String", "Value": "sns"}}}}]}`:  "sns",
+		`{"detail-type": "created", "detail": {"correlationId": "eventbridge"}}`:                                "eventbridge",
+		`{"detail": {"correlationId": "not eventbridge"}}`:                                                      "generated",
+		`"not an object"`: "generated",
+	} {
+		assert.Equal(t, expected, resolveCorrelationID(ctx, decodeEvent([]byte(event)), DefaultCorrelationSources, never), event)
+	}
+	custom := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		ClientContext: lambdacontext.ClientContext{Custom: map[string]string{"correlationId": "custom"}},
+	})
+	assert.Equal(t, "custom", resolveCorrelationID(custom, decodeEvent([]byte("{}")), DefaultCorrelationSources, never))
+	nested := EventBridgeSource("metadata.id")
+	assert.Equal(t, "nested", resolveCorrelationID(ctx, decodeEvent([]byte(`{"detail-type": "x", "detail": {"metadata": {"id": "nested"}}}`)), []CorrelationSource{nested}, never))
+}
+
+func TestNewCorrelationID(t *testing.T) {
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), NewCorrelationID())
+	assert.NotEqual(t, NewCorrelationID(), NewCorrelationID())
+}
+
+func TestMiddlewareCorrelation(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), lc)
+	core, logs := observer.New(zap.InfoLevel)
+	var inHandler string
+	h := NewMiddleware(zap.New(core), New().With(AwsRequestID, CorrelationID), Correlation(nil)).WrapFunc(func(ctx context.Context) error {
+		inHandler = CorrelationIDFromContext(ctx)
+		Info(ctx, "hello")
+		return nil
+	})
+	_, err := h.Invoke(ctx, []byte(`{"headers": {"X-Correlation-Id": "abc"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", inHandler)
+	assert.Equal(t, "abc", logs.All()[0].ContextMap()["correlationId"])
+
+	_, err = h.Invoke(ctx, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Len(t, inHandler, 36)
+
+	// Without Correlation the field is the request id
+	logger, tw := getLogger()
+	logger.Info("test", New().With(CorrelationID).ContextValues(ctx)...)
+	assert.Equal(t, "dummyid", tw.value["correlationId"])
+}
@@ -0,0 +1,216 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LazyFields computed only when an entry is written
+type LazyFields func(ctx context.Context) []zapcore.Field
+
+type ctxLoggerKey struct{}
+
+// ctxLogger the request scoped logger. skip is the same logger skipping the package level funcs for the caller
+type ctxLogger struct {
+	logger *zap.Logger
+	skip   *zap.Logger
+	lazy   []LazyFields
+}
+
+func newCtxLogger(logger *zap.Logger, lazy []LazyFields) *ctxLogger {
+	return &ctxLogger{logger: logger, skip: logger.WithOptions(zap.AddCallerSkip(2)), lazy: lazy}
+}
+
+var fallback struct {
+	sync.RWMutex
+	logger *ctxLogger
+}
+
+// SetFallbackLogger used by Info etc. when the context has no logger. Default zap.L()
+func SetFallbackLogger(logger *zap.Logger) {
+	fallback.Lock()
+	defer fallback.Unlock()
+	if logger == nil {
+		fallback.logger = nil
+		return
+	}
+	fallback.logger = newCtxLogger(logger, nil)
+}
+
+// WithLogger a context with the request scoped logger. The Middleware adds one with the lambda fields
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	var lazy []LazyFields
+	if cl, ok := ctx.Value(ctxLoggerKey{}).(*ctxLogger); ok {
+		lazy = cl.lazy
+	}
+	return context.WithValue(ctx, ctxLoggerKey{}, newCtxLogger(logger, lazy))
+}
+
+// WithFields a context whose logger has these fields
+func WithFields(ctx context.Context, fields ...zapcore.Field) context.Context {
+	return WithLogger(ctx, Logger(ctx).With(fields...))
+}
+
+// WithLazyFields a context whose entries also have the fields of lazy, computed when the entry is written
+func WithLazyFields(ctx context.Context, lazy LazyFields) context.Context {
+	cl := loggerFrom(ctx)
+	lazies := make([]LazyFields, len(cl.lazy), len(cl.lazy)+1)
+	copy(lazies, cl.lazy)
+	return context.WithValue(ctx, ctxLoggerKey{}, &ctxLogger{logger: cl.logger, skip: cl.skip, lazy: append(lazies, lazy)})
+}
+
+// Logger the request scoped logger of ctx, or the fallback logger. Lazy fields are not added
+func Logger(ctx context.Context) *zap.Logger {
+	return loggerFrom(ctx).logger
+}
+
+func loggerFrom(ctx context.Context) *ctxLogger {
+	if cl, ok := ctx.Value(ctxLoggerKey{}).(*ctxLogger); ok {
+		return cl
+	}
+	fallback.RLock()
+	defer fallback.RUnlock()
+	if fallback.logger != nil {
+		return fallback.logger
+	}
+	return newCtxLogger(zap.L(), nil)
+}
+
+func (cl *ctxLogger) write(ctx context.Context, ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	if len(cl.lazy) == 0 {
+		ce.Write(fields...)
+		return
+	}
+	// don't append to the caller's slice
+	all := make([]zapcore.Field, len(fields), len(fields)+len(cl.lazy))
+	copy(all, fields)
+	for _, lazy := range cl.lazy {
+		all = append(all, lazy(ctx)...)
+	}
+	ce.Write(all...)
+}
+
+func (cl *ctxLogger) log(ctx context.Context, l zapcore.Level, msg string, fields []zapcore.Field) {
+	if ce := cl.skip.Check(l, msg); ce != nil {
+		cl.write(ctx, ce, fields)
+	}
+}
+
+func (cl *ctxLogger) logw(ctx context.Context, l zapcore.Level, msg string, keysAndValues []interface{}) {
+	if ce := cl.skip.Check(l, msg); ce != nil {
+		cl.write(ctx, ce, sugarFields(keysAndValues))
+	}
+}
+
+// BadKey is the key of a value without a key, e.g. in Infow or lambdazaplogr's WithValues
+const BadKey = "!BADKEY"
+
+// sugarFields alternating keys and values like zap's SugaredLogger, zapcore.Fields are added as they are
+func sugarFields(keysAndValues []interface{}) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); {
+		if f, ok := keysAndValues[i].(zapcore.Field); ok {
+			fields = append(fields, f)
+			i++
+			continue
+		}
+		if i == len(keysAndValues)-1 {
+			fields = append(fields, zap.Any(BadKey, keysAndValues[i]))
+			break
+		}
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+		i += 2
+	}
+	return fields
+}
+
+func (cl *ctxLogger) logf(ctx context.Context, l zapcore.Level, template string, args []interface{}) {
+	if !cl.skip.Core().Enabled(l) {
+		return
+	}
+	if ce := cl.skip.Check(l, fmt.Sprintf(template, args...)); ce != nil {
+		cl.write(ctx, ce, nil)
+	}
+}
+
+// Debug log at debug level with the logger of ctx
+func Debug(ctx context.Context, msg string, fields ...zapcore.Field) {
+	loggerFrom(ctx).log(ctx, zapcore.DebugLevel, msg, fields)
+}
+
+// Info log at info level with the logger of ctx
+func Info(ctx context.Context, msg string, fields ...zapcore.Field) {
+	loggerFrom(ctx).log(ctx, zapcore.InfoLevel, msg, fields)
+}
+
+// Warn log at warn level with the logger of ctx
+func Warn(ctx context.Context, msg string, fields ...zapcore.Field) {
+	loggerFrom(ctx).log(ctx, zapcore.WarnLevel, msg, fields)
+}
+
+// Error log at error level with the logger of ctx
+func Error(ctx context.Context, msg string, fields ...zapcore.Field) {
+	loggerFrom(ctx).log(ctx, zapcore.ErrorLevel, msg, fields)
+}
+
+// Debugw log alternating keys and values, or zapcore.Fields, at debug level with the logger of ctx
+func Debugw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	loggerFrom(ctx).logw(ctx, zapcore.DebugLevel, msg, keysAndValues)
+}
+
+// Infow log alternating keys and values, or zapcore.Fields, at info level with the logger of ctx
+func Infow(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	loggerFrom(ctx).logw(ctx, zapcore.InfoLevel, msg, keysAndValues)
+}
+
+// Warnw log alternating keys and values, or zapcore.Fields, at warn level with the logger of ctx
+func Warnw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	loggerFrom(ctx).logw(ctx, zapcore.WarnLevel, msg, keysAndValues)
+}
+
+// Errorw log alternating keys and values, or zapcore.Fields, at error level with the logger of ctx
+func Errorw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	loggerFrom(ctx).logw(ctx, zapcore.ErrorLevel, msg, keysAndValues)
+}
+
+// Debugf log a formatted message at debug level with the logger of ctx
+func Debugf(ctx context.Context, template string, args ...interface{}) {
+	loggerFrom(ctx).logf(ctx, zapcore.DebugLevel, template, args)
+}
+
+// Infof log a formatted message at info level with the logger of ctx
+func Infof(ctx context.Context, template string, args ...interface{}) {
+	loggerFrom(ctx).logf(ctx, zapcore.InfoLevel, template, args)
+}
+
+// Warnf log a formatted message at warn level with the logger of ctx
+func Warnf(ctx context.Context, template string, args ...interface{}) {
+	loggerFrom(ctx).logf(ctx, zapcore.WarnLevel, template, args)
+}
+
+// Errorf log a formatted message at error level with the logger of ctx
+func Errorf(ctx context.Context, template string, args ...interface{}) {
+	loggerFrom(ctx).logf(ctx, zapcore.ErrorLevel, template, args)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestContextLogger(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, logs := observer.New(zap.InfoLevel)
+	calls := 0
+	h := NewMiddleware(zap.New(core, zap.AddCaller()), New().With(AwsRequestID)).WrapFunc(func(ctx context.Context) error {
+		ctx = WithLazyFields(ctx, func(ctx context.Context) []zapcore.Field {
+			calls++
+			return []zapcore.Field{zap.Int("lazy", calls)}
+		})
+		Debug(ctx, "not logged")
+		Debugf(ctx, "not %s", "logged")
+		Info(ctx, "info", zap.String("a", "b"))
+		Infow(ctx, "infow", "a", "b", zap.Int("c", 1))
+		Infof(ctx, "infof %d", 1)
+		Warn(WithFields(ctx, zap.String("d", "e")), "warn")
+		Error(ctx, "error")
+		return nil
+	})
+	_, err := h.Invoke(lc, []byte("{}"))
+	assert.NoError(t, err)
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 5)
+	assert.Equal(t, 5, calls)
+	for _, e := range entries {
+		assert.Equal(t, "dummyid", e.ContextMap()["requestId"])
+		assert.Contains(t, e.Caller.File, "ctxlog_test.go")
+	}
+	assert.Equal(t, "b", entries[0].ContextMap()["a"])
+	assert.Equal(t, int64(1), entries[1].ContextMap()["c"])
+	assert.Equal(t, "infof 1", entries[2].Message)
+	assert.Equal(t, "e", entries[3].ContextMap()["d"])
+	assert.Equal(t, int64(5), entries[4].ContextMap()["lazy"])
+}
+
+func TestSugarFields(t *testing.T) {
+	fields := sugarFields([]interface{}{zap.Int("a", 1), "b", 2, zap.String("c", "d"), "odd"})
+	if assert.Len(t, fields, 4) {
+		assert.Equal(t, []string{"a", "b", "c", BadKey}, []string{fields[0].Key, fields[1].Key, fields[2].Key, fields[3].Key})
+		assert.Equal(t, "d", fields[2].String)
+	}
+}
+
+func TestFallbackLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	SetFallbackLogger(zap.New(core))
+	defer SetFallbackLogger(nil)
+	Info(context.Background(), "fallback")
+	Errorw(context.Background(), "odd", "a")
+	assert.Equal(t, 2, logs.Len())
+	assert.Equal(t, "a", logs.All()[1].ContextMap()[BadKey])
+
+	SetFallbackLogger(nil)
+	assert.Equal(t, zap.L(), Logger(context.Background()))
+}
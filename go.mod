@@ -1,11 +1,22 @@
 module github.com/dougEfresh/lambdazap
 
-go 1.13
+go 1.21
 
 require (
 	github.com/aws/aws-lambda-go v1.13.2
-	github.com/stretchr/testify v1.4.0
+	github.com/go-logr/logr v1.4.2
+	github.com/stretchr/testify v1.8.4
+	go.opentelemetry.io/otel/trace v1.24.0
+	go.uber.org/zap v1.10.0
+	gopkg.in/yaml.v2 v2.4.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel v1.24.0 // indirect
 	go.uber.org/atomic v1.4.0 // indirect
 	go.uber.org/multierr v1.2.0 // indirect
-	go.uber.org/zap v1.10.0
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,120 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// invocationRegistry the fields of the active invocation.
+// The lambda runtime handles one invocation at a time, so there is only one
+type invocationRegistry struct {
+	sync.RWMutex
+	fields []zapcore.Field
+}
+
+var invocations = &invocationRegistry{}
+
+// BeginInvocation make the fields of lc for ctx the active invocation's until end is called.
+// The Middleware calls it, use it when handling invocations without the Middleware
+func BeginInvocation(ctx context.Context, lc *LambdaLogContext) (end func()) {
+	ctxValues := lc.ContextValues(ctx)
+	fields := make([]zapcore.Field, len(ctxValues))
+	copy(fields, ctxValues)
+	invocations.Lock()
+	defer invocations.Unlock()
+	prev := invocations.fields
+	invocations.fields = fields
+	return func() {
+		invocations.Lock()
+		defer invocations.Unlock()
+		invocations.fields = prev
+	}
+}
+
+// InvocationFields of the active invocation, empty when there is none
+func InvocationFields() []zapcore.Field {
+	invocations.RLock()
+	defer invocations.RUnlock()
+	return invocations.fields
+}
+
+type invocationCore struct {
+	zapcore.Core
+	fallback []zapcore.Field
+	// keys added with With
+	keys []string
+}
+
+// NewInvocationCore add the active invocation's fields to every entry written to core,
+// or fallback when no invocation is active. Keys already in the entry or added with With are not repeated.
+// Loggers built from it, including zap.L() after zap.ReplaceGlobals, don't need ContextValues
+func NewInvocationCore(core zapcore.Core, fallback ...zapcore.Field) zapcore.Core {
+	return &invocationCore{Core: core, fallback: fallback}
+}
+
+func (c *invocationCore) With(fields []zapcore.Field) zapcore.Core {
+	keys := make([]string, len(c.keys), len(c.keys)+len(fields))
+	copy(keys, c.keys)
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	return &invocationCore{Core: c.Core.With(fields), fallback: c.fallback, keys: keys}
+}
+
+func (c *invocationCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *invocationCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	extra := InvocationFields()
+	if len(extra) == 0 {
+		extra = c.fallback
+	}
+	if len(extra) == 0 {
+		return c.Core.Write(ent, fields)
+	}
+	all := make([]zapcore.Field, 0, len(extra)+len(fields))
+	for _, f := range extra {
+		if !hasKey(fields, f.Key) && !c.hasWithKey(f.Key) {
+			all = append(all, f)
+		}
+	}
+	return c.Core.Write(ent, append(all, fields...))
+}
+
+func (c *invocationCore) hasWithKey(key string) bool {
+	for _, k := range c.keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func hasKey(fields []zapcore.Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestInvocationCore(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewInvocationCore(core, zap.String("requestId", "none"))).With(zap.String("a", "b"))
+
+	logger.Info("before")
+	end := BeginInvocation(lc, New().With(AwsRequestID, CognitoIdentityID))
+	logger.Info("during")
+	logger.Info("explicit", zap.String("cognitoIdentityId", "mine"))
+	logger.Debug("not logged")
+	logger.With(zap.String("requestId", "bound")).Info("with")
+	end()
+	logger.Info("after")
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 5)
+	assert.Equal(t, "none", entries[0].ContextMap()["requestId"])
+	assert.Equal(t, "dummyid", entries[1].ContextMap()["requestId"])
+	assert.Equal(t, "dummyident", entries[1].ContextMap()["cognitoIdentityId"])
+	assert.Equal(t, "b", entries[1].ContextMap()["a"])
+	assert.Equal(t, "mine", entries[2].ContextMap()["cognitoIdentityId"])
+	assert.Len(t, entries[2].Context, 3)
+	assert.Len(t, entries[3].Context, 3)
+	assert.Equal(t, "bound", entries[3].ContextMap()["requestId"])
+	assert.Equal(t, "none", entries[4].ContextMap()["requestId"])
+	assert.Empty(t, InvocationFields())
+}
+
+func TestMiddlewareInvocation(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(NewInvocationCore(core))
+	h := NewMiddleware(logger, New().With(AwsRequestID)).WrapFunc(func(ctx context.Context) error {
+		logger.Info("in handler")
+		return nil
+	})
+	_, err := h.Invoke(lc, []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, logs.FilterField(zap.String("requestId", "dummyid")).Len())
+	assert.Empty(t, InvocationFields())
+}
@@ -17,6 +17,7 @@ package lambdazap
 import (
 	"context"
 	"os"
+	"sync/atomic"
 
 	"github.com/aws/aws-lambda-go/lambdacontext"
 	"go.uber.org/zap"
@@ -44,8 +45,6 @@ const (
 	END
 )
 
-const staticStartIndex = 8
-
 // DefaultNames of fields
 var DefaultNames = []string{
 	AwsRequestID:          "requestId",
@@ -82,14 +81,22 @@ func CustomNames(n map[LambdaField]string) Option {
 }
 
 // CustomValues override context values. if you return an error the default ContextValue will be used.
-func CustomValues(c ContextValuer) Option {
+// Several valuers, or CustomValues used more than once, are asked in order. See ChainValuers
+func CustomValues(c ...ContextValuer) Option {
 	return optionFunc(func(lc *LambdaLogContext) {
-		lc.customBuilder = c
+		if lc.customBuilder != nil {
+			c = append([]ContextValuer{lc.customBuilder}, c...)
+		}
+		if len(c) == 1 {
+			lc.customBuilder = c[0]
+		} else {
+			lc.customBuilder = ChainValuers(c...)
+		}
 	})
 }
 
 // ProcessNonContextFields when calling ContextValues include or not include
-// Values that are not part of the lambda context
+// Values that are not part of the lambda context, e.g. lambdacontext.FunctionName and WithEnv values
 func ProcessNonContextFields(b bool) Option {
 	return optionFunc(func(lc *LambdaLogContext) {
 		lc.processNonContextValues = b
@@ -104,24 +111,46 @@ type ContextValuer interface {
 // LambdaLogContext structure
 type LambdaLogContext struct {
 	customBuilder           ContextValuer
+	typedBuilder            TypedContextValuer
+	customTypes             map[string]ValueType
 	customNames             map[LambdaField]string
-	ctxFields               map[int]int
+	transforms              map[LambdaField]Transformer
+	keyTransforms           map[string]Transformer
+	entries                 []entry
 	staticFields            []zapcore.Field
 	fields                  []zapcore.Field
 	processNonContextValues bool
+	order                   Order
+	collisions              CollisionPolicy
+	reserved                map[string]bool
+	naming                  NamingConvention
+	prefix                  string
+	collisionsLogged        uint32
+}
+
+// entry of a field added with With, WithEnv or WithCustom.
+// name is the WithEnv or WithCustom name, key may differ after a collision.
+// index is its position in fields, -1 when it is only a NonContextValue. value is set for StaticKind and EnvKind
+type entry struct {
+	field   LambdaField
+	kind    FieldKind
+	name    string
+	key     string
+	resolve Resolver
+	index   int
+	value   zapcore.Field
 }
 
 var emptyvalues = make([]zapcore.Field, 0)
 
 // New Create a new LambdaLogContext
 func New(options ...Option) *LambdaLogContext {
-	l := &LambdaLogContext{processNonContextValues: true}
+	l := &LambdaLogContext{processNonContextValues: true, reserved: defaultReserved()}
 	if len(options) > 0 {
 		l.WithOptions(options...)
 	}
 	l.fields = make([]zapcore.Field, 0)
 	l.staticFields = make([]zapcore.Field, 0)
-	l.ctxFields = make(map[int]int)
 	return l
 }
 
@@ -133,112 +162,306 @@ func (lc *LambdaLogContext) WithOptions(opts ...Option) *LambdaLogContext {
 	return lc
 }
 
+// Field groups
+var (
+	// BasicFields request id, function name, version and arn, log group and stream
+	BasicFields = []LambdaField{AwsRequestID, FunctionName, FunctionVersion, InvokeFunctionArn, LogGroupName, LogStreamName}
+	// IdentityFields the cognito identity of the caller
+	IdentityFields = []LambdaField{CognitoIdentityID, CognitoIdentityPoolID}
+	// ClientAppFields the mobile client application of the caller
+	ClientAppFields = []LambdaField{InstallationID, AppTitle, AppVersionCode, AppPackageName}
+	// RuntimeFields the non context values of the function
+	RuntimeFields = []LambdaField{FunctionName, FunctionVersion, LogGroupName, LogStreamName, MemoryLimitInMB}
+)
+
 // WithBasic Add basic logging context
-// See ...
+// See BasicFields
 func (lc *LambdaLogContext) WithBasic() *LambdaLogContext {
-	return lc.With(AwsRequestID, FunctionName, FunctionVersion, InvokeFunctionArn, LogGroupName, LogStreamName)
+	return lc.With(BasicFields...)
+}
+
+// WithIdentity Add the cognito identity
+// See IdentityFields
+func (lc *LambdaLogContext) WithIdentity() *LambdaLogContext {
+	return lc.With(IdentityFields...)
+}
+
+// WithClientApp Add the client application
+// See ClientAppFields
+func (lc *LambdaLogContext) WithClientApp() *LambdaLogContext {
+	return lc.With(ClientAppFields...)
+}
+
+// WithRuntime Add the function's non context values
+// See RuntimeFields
+func (lc *LambdaLogContext) WithRuntime() *LambdaLogContext {
+	return lc.With(RuntimeFields...)
 }
 
 // WithAll Add all fields to  logging context
-// See ...
+// See BasicFields, IdentityFields, ClientAppFields and MemoryLimitInMB
 func (lc *LambdaLogContext) WithAll() *LambdaLogContext {
-	return lc.WithBasic().With(CognitoIdentityID, CognitoIdentityPoolID, InstallationID, AppTitle, AppVersionCode, AppPackageName, MemoryLimitInMB)
+	return lc.WithBasic().WithIdentity().WithClientApp().With(MemoryLimitInMB)
 }
 
-func (lc *LambdaLogContext) getName(l LambdaField) string {
-	n, ok := lc.customNames[l]
-	if !ok {
-		return DefaultNames[l]
+func (lc *LambdaLogContext) getName(l LambdaField, def FieldDef) string {
+	if n, ok := lc.customNames[l]; ok {
+		return n
+	}
+	if l < END {
+		return lc.keyName(DefaultNames[l])
+	}
+	return lc.keyName(def.Name)
+}
+
+func (lc *LambdaLogContext) add(e entry, field zapcore.Field) {
+	atomic.StoreUint32(&lc.collisionsLogged, 0)
+	e = lc.resolveCollision(e)
+	field.Key = e.key
+	static := e.kind == StaticKind || e.kind == EnvKind
+	if static {
+		e.value = field
+		lc.staticFields = append(lc.staticFields, field)
+	}
+	e.index = -1
+	if !static || lc.processNonContextValues {
+		e.index = len(lc.fields)
+		lc.fields = append(lc.fields, field)
 	}
-	return n
+	lc.entries = append(lc.entries, e)
 }
 
 var dummyCtx = &lambdacontext.LambdaContext{}
 
 // With Add these fields to context Add static fields if processNonContextValues is true
 func (lc *LambdaLogContext) With(fields ...LambdaField) *LambdaLogContext {
-	var ctxFieldIndex = len(lc.fields)
-
 	for _, f := range fields {
-		//		fmt.Fprintln(os.Stderr, "Adding field ", f, " index ", ctxFieldIndex)
-		if int(f) >= staticStartIndex {
-			field := zap.String(lc.getName(f), Extract(dummyCtx, f))
-			if f == MemoryLimitInMB {
-				// Speical case : Memory is an int
-				field = zap.Int(lc.getName(f), lambdacontext.MemoryLimitInMB)
-			}
-			lc.staticFields = append(lc.staticFields, field)
-			if lc.processNonContextValues {
-				lc.ctxFields[int(f)] = ctxFieldIndex
-				lc.fields = append(lc.fields, field)
-				ctxFieldIndex++
-			}
+		def, ok := fieldDef(f)
+		if !ok || lc.dropped(f) {
+			continue
+		}
+		e := entry{field: f, kind: def.Kind, key: lc.getName(f, def), resolve: def.Resolve}
+		if def.Kind == StaticKind {
+			lc.add(e, lc.resolveField(context.Background(), dummyCtx, e))
 		} else {
-			lc.ctxFields[int(f)] = ctxFieldIndex
-			lc.fields = append(lc.fields, zap.String(lc.getName(f), ""))
-			ctxFieldIndex++
+			lc.add(e, zap.String(e.key, ""))
 		}
 	}
+	lc.sortEntries()
 	return lc
 }
 
+// Clone returns a copy which can be changed without affecting lc
+func (lc *LambdaLogContext) Clone() *LambdaLogContext {
+	c := *lc
+	c.customNames = make(map[LambdaField]string, len(lc.customNames))
+	for k, v := range lc.customNames {
+		c.customNames[k] = v
+	}
+	c.transforms = make(map[LambdaField]Transformer, len(lc.transforms))
+	for k, v := range lc.transforms {
+		c.transforms[k] = v
+	}
+	c.keyTransforms = make(map[string]Transformer, len(lc.keyTransforms))
+	for k, v := range lc.keyTransforms {
+		c.keyTransforms[k] = v
+	}
+	c.customTypes = make(map[string]ValueType, len(lc.customTypes))
+	for k, v := range lc.customTypes {
+		c.customTypes[k] = v
+	}
+	c.reserved = make(map[string]bool, len(lc.reserved))
+	for k, v := range lc.reserved {
+		c.reserved[k] = v
+	}
+	c.entries = append(make([]entry, 0, len(lc.entries)), lc.entries...)
+	c.fields = append(make([]zapcore.Field, 0, len(lc.fields)), lc.fields...)
+	c.staticFields = append(make([]zapcore.Field, 0, len(lc.staticFields)), lc.staticFields...)
+	return &c
+}
+
+// CloneWith like With on a Clone, lc is unchanged
+func (lc *LambdaLogContext) CloneWith(fields ...LambdaField) *LambdaLogContext {
+	return lc.Clone().With(fields...)
+}
+
+// CloneWithEnv like WithEnv on a Clone, lc is unchanged
+func (lc *LambdaLogContext) CloneWithEnv(names ...string) *LambdaLogContext {
+	return lc.Clone().WithEnv(names...)
+}
+
+// CloneWithCustom like WithCustom on a Clone, lc is unchanged
+func (lc *LambdaLogContext) CloneWithCustom(names ...string) *LambdaLogContext {
+	return lc.Clone().WithCustom(names...)
+}
+
+// Without returns a Clone without these fields, lc is unchanged
+func (lc *LambdaLogContext) Without(fields ...LambdaField) *LambdaLogContext {
+	remove := make(map[LambdaField]bool, len(fields))
+	for _, f := range fields {
+		remove[f] = true
+	}
+	return lc.without(func(e entry) bool {
+		return e.kind != EnvKind && e.kind != ClientCustomKind && remove[e.field]
+	})
+}
+
+// WithoutKeys returns a Clone without the fields named by keys, e.g. from WithEnv or WithCustom. lc is unchanged
+func (lc *LambdaLogContext) WithoutKeys(keys ...string) *LambdaLogContext {
+	remove := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		remove[k] = true
+	}
+	return lc.without(func(e entry) bool {
+		return remove[e.key]
+	})
+}
+
+func (lc *LambdaLogContext) without(remove func(e entry) bool) *LambdaLogContext {
+	c := lc.Clone()
+	entries := make([]entry, 0, len(lc.entries))
+	for _, e := range lc.entries {
+		if !remove(e) {
+			entries = append(entries, e)
+		}
+	}
+	c.rebuild(entries)
+	return c
+}
+
+// rebuild fields and staticFields from entries, keeping the current values
+func (lc *LambdaLogContext) rebuild(entries []entry) {
+	fields := lc.fields
+	lc.entries = make([]entry, 0, len(entries))
+	lc.fields = make([]zapcore.Field, 0, len(fields))
+	lc.staticFields = make([]zapcore.Field, 0, len(lc.staticFields))
+	for _, e := range entries {
+		field := e.value
+		if e.index >= 0 {
+			field = fields[e.index]
+		}
+		lc.add(e, field)
+	}
+}
+
 // NonContextValues e.g. lambdacontext.FunctionName or os.Getenv
 func (lc *LambdaLogContext) NonContextValues() []zapcore.Field {
 	return lc.staticFields
 }
 
+// Keys of all fields added with With, WithEnv and WithCustom
+func (lc *LambdaLogContext) Keys() []string {
+	keys := make([]string, 0, len(lc.fields)+len(lc.staticFields))
+	seen := make(map[string]bool)
+	for _, fields := range [][]zapcore.Field{lc.fields, lc.staticFields} {
+		for _, f := range fields {
+			if !seen[f.Key] {
+				seen[f.Key] = true
+				keys = append(keys, f.Key)
+			}
+		}
+	}
+	return keys
+}
+
+// KeyOf the key f is logged with, the key it would be logged with when it was not added
+func (lc *LambdaLogContext) KeyOf(f LambdaField) string {
+	for _, e := range lc.entries {
+		if e.field == f && e.name == "" {
+			return e.key
+		}
+	}
+	def, _ := fieldDef(f)
+	return lc.getName(f, def)
+}
+
 // ContextValue get the context value for a field
 func (lc *LambdaLogContext) ContextValue(ctx *lambdacontext.LambdaContext, f LambdaField) string {
-	if lc.customBuilder != nil {
+	if lc.customBuilder != nil && handles(lc.customBuilder, f) {
 		v, err := lc.customBuilder.ContextValue(ctx, f)
 		if err == nil {
-			return v
+			return lc.transform(f, v)
 		}
 	}
-	return Extract(ctx, f)
+	return lc.transform(f, Extract(ctx, f))
 }
 
 // ContextValues for the lambda context.
+// The returned slice is reused by the next call, use AppendContextValues from concurrent goroutines
 func (lc *LambdaLogContext) ContextValues(ctx context.Context) []zapcore.Field {
 	lcv, ok := lambdacontext.FromContext(ctx)
-	if len(lc.ctxFields) == 0 || !ok {
+	if len(lc.entries) == 0 || !ok {
 		return emptyvalues
 	}
-	for k, v := range lc.ctxFields {
-		if k < int(END) {
-			if LambdaField(k) < staticStartIndex {
-				lc.fields[v].String = lc.ContextValue(lcv, LambdaField(k))
-			}
+	for _, e := range lc.entries {
+		if e.index < 0 {
+			continue
 		}
-		if k >= 200 {
-			//Custom fields start at 200
-			lc.fields[v].String = lcv.ClientContext.Custom[lc.fields[v].Key]
+		switch e.kind {
+		case InvocationKind, UserKind:
+			lc.fields[e.index] = lc.resolveField(ctx, lcv, e)
+		case ClientCustomKind:
+			lc.fields[e.index] = lc.customField(e.name, e.key, lcv.ClientContext.Custom[e.name])
 		}
 	}
 	return lc.fields
 }
 
+// AppendContextValues appends the ContextValues of ctx to dst without changing lc, safe for concurrent use
+func (lc *LambdaLogContext) AppendContextValues(dst []zapcore.Field, ctx context.Context) []zapcore.Field {
+	lcv, ok := lambdacontext.FromContext(ctx)
+	if len(lc.entries) == 0 || !ok {
+		return dst
+	}
+	base := len(dst)
+	dst = append(dst, lc.fields...)
+	for _, e := range lc.entries {
+		if e.index < 0 {
+			continue
+		}
+		switch e.kind {
+		case InvocationKind, UserKind:
+			dst[base+e.index] = lc.resolveField(ctx, lcv, e)
+		case ClientCustomKind:
+			dst[base+e.index] = lc.customField(e.name, e.key, lcv.ClientContext.Custom[e.name])
+		}
+	}
+	return dst
+}
+
+// resolveField with the field's resolver, or the valuers for the lambda context fields
+func (lc *LambdaLogContext) resolveField(ctx context.Context, lcv *lambdacontext.LambdaContext, e entry) zapcore.Field {
+	if e.resolve == nil {
+		return lc.contextField(lcv, e.field, e.key)
+	}
+	field := e.resolve(ctx, lcv, e.key)
+	if field.Type == zapcore.StringType {
+		field.String = lc.transform(e.field, field.String)
+	}
+	return field
+}
+
 // WithEnv Add Env from os.Getenv
 func (lc *LambdaLogContext) WithEnv(names ...string) *LambdaLogContext {
-	var start = len(lc.fields)
 	for _, n := range names {
-		lc.ctxFields[start+100] = start
-		f := zap.String(n, os.Getenv(n))
-		lc.staticFields = append(lc.staticFields, f)
-		lc.fields = append(lc.fields, f)
-		start++
+		if lc.droppedKey(n) {
+			continue
+		}
+		lc.add(entry{kind: EnvKind, name: n, key: lc.keyName(n)}, zap.String(n, lc.transformKey(n, os.Getenv(n))))
 	}
+	lc.sortEntries()
 	return lc
 }
 
 // WithCustom Add names from lambdacontext.ClientContext.Custom
 func (lc *LambdaLogContext) WithCustom(names ...string) *LambdaLogContext {
-	var start = len(lc.fields)
 	for _, n := range names {
-		lc.ctxFields[start+200] = start
-		lc.fields = append(lc.fields, zap.String(n, ""))
-		start++
+		if lc.droppedKey(n) {
+			continue
+		}
+		lc.add(entry{kind: ClientCustomKind, name: n, key: lc.keyName(n)}, zap.String(n, ""))
 	}
+	lc.sortEntries()
 	return lc
 }
 
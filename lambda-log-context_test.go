@@ -288,3 +288,77 @@ func reset() {
 	lambdacontext.LogGroupName = ""
 	lambdacontext.LogStreamName = ""
 }
+
+func TestCloneWith(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	lc, cf := getContext()
+	defer cf()
+	base := New().With(AwsRequestID).WithEnv("SHELL")
+	withArn := base.CloneWith(InvokeFunctionArn).CloneWithCustom("custom1")
+	assert.Len(t, base.ContextValues(lc), 2)
+	assert.Len(t, withArn.ContextValues(lc), 4)
+	assert.Len(t, base.Clone().ContextValues(lc), 2)
+}
+
+func TestWithout(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	lc, cf := getContext()
+	defer cf()
+	base := New().WithBasic().WithEnv("SHELL").WithCustom("custom1")
+	lf := base.Without(FunctionName, InvokeFunctionArn).WithoutKeys("SHELL", "custom1")
+	logger, tw := getLogger()
+	logger = logger.With(lf.NonContextValues()...)
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Len(t, tw.value, 5)
+	assert.Equal(t, "dummyid", tw.value["requestId"])
+	assert.NotContains(t, tw.value, "functionName")
+	assert.NotContains(t, tw.value, "arn")
+	assert.Len(t, base.ContextValues(lc), 8)
+	assert.Len(t, base.NonContextValues(), 5)
+	assert.Len(t, lf.NonContextValues(), 3)
+}
+
+func TestGroups(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	lc, cf := getContext()
+	defer cf()
+	lf := New(ProcessNonContextFields(false)).WithIdentity().WithClientApp().WithRuntime()
+	logger, tw := getLogger()
+	logger = logger.With(lf.NonContextValues()...)
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Len(t, tw.value, 1+len(IdentityFields)+len(ClientAppFields)+len(RuntimeFields))
+	assert.Equal(t, "dummyident", tw.value["cognitoIdentityId"])
+	assert.Equal(t, "dummytitle", tw.value["appTitle"])
+	assert.Equal(t, float64(128), tw.value["memoryLimitInMB"])
+	assert.Len(t, lf.ContextValues(lc), len(IdentityFields)+len(ClientAppFields))
+}
+
+func TestKeyOf(t *testing.T) {
+	lf := New(CustomNames(map[LambdaField]string{AwsRequestID: "rid"})).With(AwsRequestID)
+	assert.Equal(t, "rid", lf.KeyOf(AwsRequestID))
+	assert.Equal(t, DefaultNames[FunctionName], lf.KeyOf(FunctionName))
+}
+
+func TestAppendContextValues(t *testing.T) {
+	ctx, cf := getContext()
+	defer cf()
+	lf := New().With(AwsRequestID).WithCustom("custom1")
+	before := append([]zapcore.Field(nil), lf.fields...)
+	dst := lf.AppendContextValues([]zapcore.Field{zap.String("first", "1")}, ctx)
+	if assert.Len(t, dst, 3) {
+		assert.Equal(t, "first", dst[0].Key)
+		assert.Equal(t, "dummyid", dst[1].String)
+		assert.Equal(t, "dummycustom1", dst[2].String)
+	}
+	assert.Equal(t, before, lf.fields)
+	assert.Empty(t, lf.AppendContextValues(nil, context.Background()))
+}
@@ -0,0 +1,101 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lambdazaplogr adapts lambdazap to logr, for libraries taking a logr.Logger.
+package lambdazaplogr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dougEfresh/lambdazap"
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogSink a logr.LogSink writing to a zap logger.
+// V(n) logs at zapcore.Level(-n), so V(0) is info and V(1) is debug. Names are added with zap's Named
+type LogSink struct {
+	logger *zap.Logger
+}
+
+var _ logr.CallDepthLogSink = (*LogSink)(nil)
+
+// NewLogSink write to logger
+func NewLogSink(logger *zap.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// NewLogr a logr.Logger with the lambda fields of ctx. Create one per invocation
+func NewLogr(ctx context.Context, logger *zap.Logger, lc *lambdazap.LambdaLogContext) logr.Logger {
+	lc.LogCollisions(logger)
+	return logr.New(NewLogSink(logger.With(lc.ContextValues(ctx)...)))
+}
+
+// Init skips the logr frames and the LogSink method when adding the caller
+func (s *LogSink) Init(info logr.RuntimeInfo) {
+	s.logger = s.logger.WithOptions(zap.AddCallerSkip(info.CallDepth + 1))
+}
+
+// Enabled reports whether V(level) is enabled
+func (s *LogSink) Enabled(level int) bool {
+	return s.logger.Core().Enabled(zapcore.Level(-level))
+}
+
+// Info log msg and keysAndValues at V(level)
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if ce := s.logger.Check(zapcore.Level(-level), msg); ce != nil {
+		ce.Write(logrFields(keysAndValues)...)
+	}
+}
+
+// Error log err, msg and keysAndValues at error level
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	if ce := s.logger.Check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Write(append(logrFields(keysAndValues), zap.Error(err))...)
+	}
+}
+
+// WithValues a new LogSink with these keys and values
+func (s *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &LogSink{logger: s.logger.With(logrFields(keysAndValues)...)}
+}
+
+// WithName a new LogSink with name appended to the logger's name
+func (s *LogSink) WithName(name string) logr.LogSink {
+	return &LogSink{logger: s.logger.Named(name)}
+}
+
+// WithCallDepth a new LogSink which skips depth more frames when adding the caller
+func (s *LogSink) WithCallDepth(depth int) logr.LogSink {
+	return &LogSink{logger: s.logger.WithOptions(zap.AddCallerSkip(depth))}
+}
+
+// logrFields alternating keys and values, a value without a key is logged as lambdazap.BadKey
+func logrFields(keysAndValues []interface{}) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i == len(keysAndValues)-1 {
+			fields = append(fields, zap.Any(lambdazap.BadKey, keysAndValues[i]))
+			break
+		}
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+	}
+	return fields
+}
@@ -0,0 +1,73 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazaplogr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dougEfresh/lambdazap"
+	"github.com/dougEfresh/lambdazap/lambdazaptest"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogr(t *testing.T) {
+	ctx, restore := lambdazaptest.NewContext().RequestID("dummyid").Custom("custom1", "dummycustom1").Build()
+	defer restore()
+	lf := lambdazap.New().With(lambdazap.AwsRequestID).WithCustom("custom1")
+	zl, logs := lambdazaptest.NewLogger(zapcore.DebugLevel, zap.AddCaller())
+	logger := NewLogr(ctx, zl, lf).WithName("lib").WithValues("a", 1)
+
+	logger.Info("info", "b", "c", "odd")
+	logger.V(1).Info("debug")
+	logger.V(2).Info("not logged")
+	logger.Error(errors.New("boom"), "error")
+	assert.False(t, logger.V(2).Enabled())
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 3)
+	for _, e := range entries {
+		assert.Equal(t, "lib", e.LoggerName)
+		assert.Contains(t, e.Caller.File, "logr_test.go")
+		m := e.ContextMap()
+		assert.Equal(t, "dummyid", m["requestId"])
+		assert.Equal(t, "dummycustom1", m["custom1"])
+		assert.Equal(t, int64(1), m["a"])
+	}
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, "c", entries[0].ContextMap()["b"])
+	assert.Equal(t, "odd", entries[0].ContextMap()[lambdazap.BadKey])
+	assert.Equal(t, zapcore.DebugLevel, entries[1].Level)
+	assert.Equal(t, zapcore.ErrorLevel, entries[2].Level)
+	assert.Equal(t, "boom", entries[2].ContextMap()["error"])
+}
+
+func TestLogrFields(t *testing.T) {
+	// fields are not spliced, unlike lambdazap's Infow
+	fields := logrFields([]interface{}{zap.Int("a", 1), "b"})
+	assert.Len(t, fields, 1)
+}
+
+func TestLogrCollisions(t *testing.T) {
+	ctx, restore := lambdazaptest.NewContext().RequestID("dummyid").Build()
+	defer restore()
+	zl, logs := lambdazaptest.NewLogger(zapcore.InfoLevel)
+	lf := lambdazap.New().With(lambdazap.AwsRequestID).WithEnv("requestId")
+	NewLogr(ctx, zl, lf)
+	NewLogr(ctx, zl, lf)
+	assert.Equal(t, 1, logs.FilterMessage("lambdazap field key collision").Len())
+}
@@ -0,0 +1,32 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lambdazapotel reads lambdazap's trace fields from the active OpenTelemetry span.
+package lambdazapotel
+
+import (
+	"context"
+
+	"github.com/dougEfresh/lambdazap"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ActiveSpan the span context of the active OpenTelemetry span of ctx. Install it with lambdazap.SetActiveSpanFunc
+func ActiveSpan(ctx context.Context) (lambdazap.SpanContext, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return lambdazap.SpanContext{}, false
+	}
+	return lambdazap.SpanContext{TraceID: sc.TraceID(), SpanID: sc.SpanID(), TraceFlags: byte(sc.TraceFlags())}, true
+}
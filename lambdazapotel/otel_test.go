@@ -0,0 +1,42 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazapotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dougEfresh/lambdazap"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestActiveSpan(t *testing.T) {
+	_, ok := ActiveSpan(context.Background())
+	assert.False(t, ok)
+
+	traceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	spanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	ctx := trace.ContextWithSpanContext(context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled}))
+
+	lambdazap.SetActiveSpanFunc(ActiveSpan)
+	defer lambdazap.SetActiveSpanFunc(nil)
+	sc, ok := lambdazap.TraceContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", sc.TraceIDString())
+	assert.Equal(t, "b7ad6b7169203331", sc.SpanIDString())
+	assert.True(t, sc.IsSampled())
+}
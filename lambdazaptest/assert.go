@@ -0,0 +1,126 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazaptest
+
+import (
+	"reflect"
+
+	"github.com/dougEfresh/lambdazap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestingT is the subset of testing.TB used by the assertions
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+type tHelper interface {
+	Helper()
+}
+
+func helper(t TestingT) {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+}
+
+// AssertRequestID every entry carries the request id under the AwsRequestID name of lc,
+// lambdazap's default name when lc is nil
+func AssertRequestID(t TestingT, logs *observer.ObservedLogs, lc *lambdazap.LambdaLogContext, id string) bool {
+	helper(t)
+	key := lambdazap.DefaultNames[lambdazap.AwsRequestID]
+	if lc != nil {
+		key = lc.KeyOf(lambdazap.AwsRequestID)
+	}
+	return AssertAllField(t, logs, key, id)
+}
+
+// AssertAllField every entry has field key with value
+func AssertAllField(t TestingT, logs *observer.ObservedLogs, key string, value interface{}) bool {
+	helper(t)
+	entries := logs.All()
+	if len(entries) == 0 {
+		t.Errorf("no log entries, expected %s=%v", key, value)
+		return false
+	}
+	ok := true
+	for i := range entries {
+		ok = assertField(t, i, entries[i], key, value) && ok
+	}
+	return ok
+}
+
+// AssertEntryField entry n (zero based) has field key with value
+func AssertEntryField(t TestingT, logs *observer.ObservedLogs, n int, key string, value interface{}) bool {
+	helper(t)
+	entries := logs.All()
+	if n < 0 || n >= len(entries) {
+		t.Errorf("entry %d does not exist, there are %d entries", n, len(entries))
+		return false
+	}
+	return assertField(t, n, entries[n], key, value)
+}
+
+// AssertEntryHasField entry n (zero based) has field key with any value
+func AssertEntryHasField(t TestingT, logs *observer.ObservedLogs, n int, key string) bool {
+	helper(t)
+	entries := logs.All()
+	if n < 0 || n >= len(entries) {
+		t.Errorf("entry %d does not exist, there are %d entries", n, len(entries))
+		return false
+	}
+	if _, ok := entries[n].ContextMap()[key]; !ok {
+		t.Errorf("entry %d %q has no field %s", n, entries[n].Message, key)
+		return false
+	}
+	return true
+}
+
+func assertField(t TestingT, n int, e observer.LoggedEntry, key string, value interface{}) bool {
+	helper(t)
+	actual, ok := e.ContextMap()[key]
+	if !ok {
+		t.Errorf("entry %d %q has no field %s", n, e.Message, key)
+		return false
+	}
+	if !equal(actual, value) {
+		t.Errorf("entry %d %q field %s: expected %v (%T) got %v (%T)", n, e.Message, key, value, value, actual, actual)
+		return false
+	}
+	return true
+}
+
+// equal compares like reflect.DeepEqual but treats all integer kinds as equal when the value matches,
+// since zap stores zap.Int as int64
+func equal(actual, expected interface{}) bool {
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+	a, aok := toInt64(actual)
+	e, eok := toInt64(expected)
+	return aok && eok && a == e
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return int64(rv.Uint()), true
+	default:
+		return 0, false
+	}
+}
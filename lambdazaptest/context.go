@@ -0,0 +1,204 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lambdazaptest provides helpers for testing code that logs with lambdazap.
+package lambdazaptest
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// statics of lambdacontext, set marks the ones to install
+type statics struct {
+	functionName    string
+	functionVersion string
+	logGroupName    string
+	logStreamName   string
+	memoryLimitInMB int
+	set             staticSet
+}
+
+type staticSet uint8
+
+const (
+	setFunctionName staticSet = 1 << iota
+	setFunctionVersion
+	setLogGroupName
+	setLogStreamName
+	setMemoryLimitInMB
+	setAll = setFunctionName | setFunctionVersion | setLogGroupName | setLogStreamName | setMemoryLimitInMB
+)
+
+func currentStatics() statics {
+	return statics{
+		functionName:    lambdacontext.FunctionName,
+		functionVersion: lambdacontext.FunctionVersion,
+		logGroupName:    lambdacontext.LogGroupName,
+		logStreamName:   lambdacontext.LogStreamName,
+		memoryLimitInMB: lambdacontext.MemoryLimitInMB,
+		set:             setAll,
+	}
+}
+
+// install the statics which are set, the others are left alone
+func (s statics) install() {
+	if s.set&setFunctionName != 0 {
+		lambdacontext.FunctionName = s.functionName
+	}
+	if s.set&setFunctionVersion != 0 {
+		lambdacontext.FunctionVersion = s.functionVersion
+	}
+	if s.set&setLogGroupName != 0 {
+		lambdacontext.LogGroupName = s.logGroupName
+	}
+	if s.set&setLogStreamName != 0 {
+		lambdacontext.LogStreamName = s.logStreamName
+	}
+	if s.set&setMemoryLimitInMB != 0 {
+		lambdacontext.MemoryLimitInMB = s.memoryLimitInMB
+	}
+}
+
+// ContextBuilder builds a fake lambdacontext.LambdaContext and the package level
+// statics of lambdacontext (FunctionName, MemoryLimitInMB ...)
+type ContextBuilder struct {
+	lc       lambdacontext.LambdaContext
+	statics  statics
+	parent   context.Context
+	deadline time.Time
+}
+
+// NewContext Create an empty ContextBuilder
+func NewContext() *ContextBuilder {
+	return &ContextBuilder{parent: context.Background()}
+}
+
+// Parent context to derive from. Defaults to context.Background()
+func (b *ContextBuilder) Parent(ctx context.Context) *ContextBuilder {
+	b.parent = ctx
+	return b
+}
+
+// Deadline of the invocation
+func (b *ContextBuilder) Deadline(d time.Time) *ContextBuilder {
+	b.deadline = d
+	return b
+}
+
+// RequestID sets AwsRequestID
+func (b *ContextBuilder) RequestID(id string) *ContextBuilder {
+	b.lc.AwsRequestID = id
+	return b
+}
+
+// InvokedFunctionArn sets InvokedFunctionArn
+func (b *ContextBuilder) InvokedFunctionArn(arn string) *ContextBuilder {
+	b.lc.InvokedFunctionArn = arn
+	return b
+}
+
+// CognitoIdentity sets the cognito identity and pool id
+func (b *ContextBuilder) CognitoIdentity(id, pool string) *ContextBuilder {
+	b.lc.Identity = lambdacontext.CognitoIdentity{CognitoIdentityID: id, CognitoIdentityPoolID: pool}
+	return b
+}
+
+// Client sets ClientContext.Client
+func (b *ContextBuilder) Client(c lambdacontext.ClientApplication) *ContextBuilder {
+	b.lc.ClientContext.Client = c
+	return b
+}
+
+// Custom adds a key to ClientContext.Custom
+func (b *ContextBuilder) Custom(key, value string) *ContextBuilder {
+	if b.lc.ClientContext.Custom == nil {
+		b.lc.ClientContext.Custom = make(map[string]string)
+	}
+	b.lc.ClientContext.Custom[key] = value
+	return b
+}
+
+// Env adds a key to ClientContext.Env
+func (b *ContextBuilder) Env(key, value string) *ContextBuilder {
+	if b.lc.ClientContext.Env == nil {
+		b.lc.ClientContext.Env = make(map[string]string)
+	}
+	b.lc.ClientContext.Env[key] = value
+	return b
+}
+
+// FunctionName sets lambdacontext.FunctionName when built
+func (b *ContextBuilder) FunctionName(n string) *ContextBuilder {
+	b.statics.functionName = n
+	b.statics.set |= setFunctionName
+	return b
+}
+
+// FunctionVersion sets lambdacontext.FunctionVersion when built
+func (b *ContextBuilder) FunctionVersion(v string) *ContextBuilder {
+	b.statics.functionVersion = v
+	b.statics.set |= setFunctionVersion
+	return b
+}
+
+// LogGroupName sets lambdacontext.LogGroupName when built
+func (b *ContextBuilder) LogGroupName(n string) *ContextBuilder {
+	b.statics.logGroupName = n
+	b.statics.set |= setLogGroupName
+	return b
+}
+
+// LogStreamName sets lambdacontext.LogStreamName when built
+func (b *ContextBuilder) LogStreamName(n string) *ContextBuilder {
+	b.statics.logStreamName = n
+	b.statics.set |= setLogStreamName
+	return b
+}
+
+// MemoryLimitInMB sets lambdacontext.MemoryLimitInMB when built
+func (b *ContextBuilder) MemoryLimitInMB(m int) *ContextBuilder {
+	b.statics.memoryLimitInMB = m
+	b.statics.set |= setMemoryLimitInMB
+	return b
+}
+
+// LambdaContext returns a copy of the lambda context being built
+func (b *ContextBuilder) LambdaContext() *lambdacontext.LambdaContext {
+	lc := b.lc
+	return &lc
+}
+
+// Build installs the statics which were set and returns a context carrying the LambdaContext.
+// Call the returned func (usually deferred) to cancel the context and restore the previous statics.
+func (b *ContextBuilder) Build() (context.Context, func()) {
+	previous := currentStatics()
+	b.statics.install()
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if b.deadline.IsZero() {
+		ctx, cancel = context.WithCancel(b.parent)
+	} else {
+		ctx, cancel = context.WithDeadline(b.parent, b.deadline)
+	}
+	ctx = lambdacontext.NewContext(ctx, b.LambdaContext())
+	return ctx, func() {
+		cancel()
+		previous.install()
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazaptest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/dougEfresh/lambdazap"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestBuildRestoresStatics(t *testing.T) {
+	lambdacontext.FunctionName = "before"
+	lambdacontext.LogGroupName = "group"
+	defer func() { lambdacontext.FunctionName, lambdacontext.LogGroupName = "", "" }()
+	ctx, restore := NewContext().
+		RequestID("dummyid").
+		CognitoIdentity("dummyident", "dummypool").
+		Custom("custom1", "dummycustom1").
+		FunctionName("dummyfunction").
+		MemoryLimitInMB(128).
+		Build()
+	lc, ok := lambdacontext.FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "dummyid", lc.AwsRequestID)
+	assert.Equal(t, "dummypool", lc.Identity.CognitoIdentityPoolID)
+	assert.Equal(t, "dummycustom1", lc.ClientContext.Custom["custom1"])
+	assert.Equal(t, "dummyfunction", lambdacontext.FunctionName)
+	assert.Equal(t, 128, lambdacontext.MemoryLimitInMB)
+	assert.Equal(t, "group", lambdacontext.LogGroupName)
+	restore()
+	assert.Equal(t, "before", lambdacontext.FunctionName)
+	assert.Equal(t, 0, lambdacontext.MemoryLimitInMB)
+	assert.Error(t, ctx.Err())
+}
+
+func TestAssertions(t *testing.T) {
+	ctx, restore := NewContext().RequestID("dummyid").FunctionName("dummyfunction").MemoryLimitInMB(128).Build()
+	defer restore()
+	lf := lambdazap.New().With(lambdazap.AwsRequestID, lambdazap.MemoryLimitInMB)
+	logger, logs := NewLogger(zap.InfoLevel)
+	logger.Info("one", lf.ContextValues(ctx)...)
+	logger.Info("two", lf.ContextValues(ctx)...)
+
+	assert.True(t, AssertRequestID(t, logs, nil, "dummyid"))
+	assert.True(t, AssertRequestID(t, logs, lf, "dummyid"))
+	assert.True(t, AssertEntryField(t, logs, 1, "memoryLimitInMB", 128))
+	assert.True(t, AssertEntryHasField(t, logs, 0, "requestId"))
+
+	r := &recorder{}
+	assert.False(t, AssertRequestID(r, logs, lf, "other"))
+	assert.False(t, AssertEntryField(r, logs, 2, "requestId", "dummyid"))
+	assert.False(t, AssertEntryHasField(r, logs, 0, "missing"))
+	assert.Len(t, r.errors, 4)
+}
+
+func TestAssertRequestIDCustomName(t *testing.T) {
+	ctx, restore := NewContext().RequestID("dummyid").Build()
+	defer restore()
+	lf := lambdazap.New(lambdazap.CustomNames(map[lambdazap.LambdaField]string{lambdazap.AwsRequestID: "rid"})).With(lambdazap.AwsRequestID)
+	logger, logs := NewLogger(zap.InfoLevel)
+	logger.Info("one", lf.ContextValues(ctx)...)
+
+	assert.True(t, AssertRequestID(t, logs, lf, "dummyid"))
+	assert.False(t, AssertRequestID(&recorder{}, logs, nil, "dummyid"))
+}
@@ -0,0 +1,244 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// A MiddlewareOption configures a Middleware.
+type MiddlewareOption interface {
+	apply(*Middleware)
+}
+
+type middlewareOptionFunc func(*Middleware)
+
+func (f middlewareOptionFunc) apply(m *Middleware) {
+	f(m)
+}
+
+// PanicLevel the level used to log a recovered panic. Default zapcore.ErrorLevel
+// zapcore.PanicLevel and above are written without zap panicking again.
+func PanicLevel(l zapcore.Level) MiddlewareOption {
+	return middlewareOptionFunc(func(m *Middleware) {
+		m.panicLevel = l
+	})
+}
+
+// RecoverPanics when true a panic is logged and returned as a *PanicError instead of re-panicking
+func RecoverPanics(b bool) MiddlewareOption {
+	return middlewareOptionFunc(func(m *Middleware) {
+		m.recoverPanics = b
+	})
+}
+
+// TimeoutMargin how long before the context deadline a "timeout imminent" entry is logged and the logger synced.
+// Default DefaultTimeoutMargin, 0 disables
+func TimeoutMargin(d time.Duration) MiddlewareOption {
+	return middlewareOptionFunc(func(m *Middleware) {
+		m.timeoutMargin = d
+	})
+}
+
+// LogPayloads log the event payload before and the response after the handler, redacted by r
+func LogPayloads(r *Redactor) MiddlewareOption {
+	return middlewareOptionFunc(func(m *Middleware) {
+		m.redactor = r
+	})
+}
+
+// Correlation resolve the correlation id of each invocation from the first of sources with one,
+// DefaultCorrelationSources when none are given. It is generated with generate, NewCorrelationID when nil, if none has one.
+// See CorrelationID and CorrelationIDFromContext
+func Correlation(generate func() string, sources ...CorrelationSource) MiddlewareOption {
+	if len(sources) == 0 {
+		sources = DefaultCorrelationSources
+	}
+	if generate == nil {
+		generate = NewCorrelationID
+	}
+	return middlewareOptionFunc(func(m *Middleware) {
+		m.correlationSources = sources
+		m.generateCorrelationID = generate
+	})
+}
+
+// Traceparent resolve the inbound W3C traceparent of each invocation from the first of sources with one,
+// DefaultTraceparentSources when none are given. See TraceFields and TraceContext
+func Traceparent(sources ...CorrelationSource) MiddlewareOption {
+	if len(sources) == 0 {
+		sources = DefaultTraceparentSources
+	}
+	return middlewareOptionFunc(func(m *Middleware) {
+		m.traceparentSources = sources
+	})
+}
+
+// DefaultTimeoutMargin see TimeoutMargin
+const DefaultTimeoutMargin = 100 * time.Millisecond
+
+// PanicError is returned by the handler when a panic is recovered. See RecoverPanics
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Middleware wraps a lambda.Handler with logging of the lambda context
+type Middleware struct {
+	logger        *zap.Logger
+	lc            *LambdaLogContext
+	panicLevel    zapcore.Level
+	recoverPanics bool
+	timeoutMargin time.Duration
+	redactor      *Redactor
+
+	correlationSources    []CorrelationSource
+	generateCorrelationID func() string
+	traceparentSources    []CorrelationSource
+}
+
+// NewMiddleware Create a middleware logging with logger and the fields of lc
+func NewMiddleware(logger *zap.Logger, lc *LambdaLogContext, options ...MiddlewareOption) *Middleware {
+	m := &Middleware{logger: logger, lc: lc, panicLevel: zapcore.ErrorLevel, timeoutMargin: DefaultTimeoutMargin}
+	for _, o := range options {
+		o.apply(m)
+	}
+	lc.LogCollisions(logger)
+	return m
+}
+
+// Wrap a lambda.Handler. Use with lambda.StartHandler
+func (m *Middleware) Wrap(h lambda.Handler) lambda.Handler {
+	return &handler{m: m, next: h}
+}
+
+// WrapFunc wrap a handler func accepted by lambda.Start
+func (m *Middleware) WrapFunc(handlerFunc interface{}) lambda.Handler {
+	return m.Wrap(lambda.NewHandler(handlerFunc))
+}
+
+type handler struct {
+	m    *Middleware
+	next lambda.Handler
+}
+
+// Invoke the next handler with the request scoped logger in ctx, see Info etc. and Go.
+// The logger is always synced before returning, Lambda may freeze the sandbox afterwards
+func (h *handler) Invoke(ctx context.Context, payload []byte) (response []byte, err error) {
+	ctx = h.m.withEventContext(ctx, payload)
+	end := BeginInvocation(ctx, h.m.lc)
+	ctx = WithLogger(ctx, h.m.logger.With(h.m.lc.ContextValues(ctx)...))
+	ctx, tasks := withTaskGroup(ctx)
+	stop := h.m.watchDeadline(ctx)
+	defer func() {
+		stop()
+		defer end()
+		tasks.end(ctx)
+		if v := recover(); v != nil {
+			err = h.m.handlePanic(ctx, v)
+			return
+		}
+		h.m.logger.Sync()
+	}()
+	if h.m.redactor == nil {
+		return h.next.Invoke(ctx, payload)
+	}
+	h.m.logPayload(ctx, "lambda event", "event", payload)
+	response, err = h.next.Invoke(ctx, payload)
+	if err == nil {
+		h.m.logPayload(ctx, "lambda response", "response", response)
+	}
+	return response, err
+}
+
+func (m *Middleware) logPayload(ctx context.Context, msg, key string, payload []byte) {
+	if ce := m.logger.Check(zapcore.InfoLevel, msg); ce != nil {
+		payloadFields := m.redactor.Fields(key, payload)
+		ctxValues := m.lc.ContextValues(ctx)
+		fields := make([]zapcore.Field, 0, len(ctxValues)+len(payloadFields))
+		fields = append(fields, ctxValues...)
+		ce.Write(append(fields, payloadFields...)...)
+	}
+}
+
+// withEventContext add the correlation id and traceparent of the event to ctx
+func (m *Middleware) withEventContext(ctx context.Context, payload []byte) context.Context {
+	if m.correlationSources == nil && m.traceparentSources == nil {
+		return ctx
+	}
+	event := decodeEvent(payload)
+	if m.correlationSources != nil {
+		ctx = WithCorrelationID(ctx, resolveCorrelationID(ctx, event, m.correlationSources, m.generateCorrelationID))
+	}
+	if tp := firstSource(ctx, event, m.traceparentSources); tp != "" {
+		ctx = WithTraceparent(ctx, tp)
+	}
+	return ctx
+}
+
+var noop = func() {}
+
+// watchDeadline log and sync timeoutMargin before the deadline. Returns a func to stop watching
+func (m *Middleware) watchDeadline(ctx context.Context) func() {
+	deadline, ok := ctx.Deadline()
+	if !ok || m.timeoutMargin <= 0 || !time.Now().Before(deadline) {
+		return noop
+	}
+	// The timer fires on another goroutine, take a copy of the fields now
+	ctxValues := m.lc.ContextValues(ctx)
+	fields := make([]zapcore.Field, len(ctxValues), len(ctxValues)+1)
+	copy(fields, ctxValues)
+	timer := time.AfterFunc(time.Until(deadline)-m.timeoutMargin, func() {
+		fields = append(fields, zap.Duration("remaining", time.Until(deadline)))
+		m.logger.Warn("timeout imminent", fields...)
+		m.logger.Sync()
+	})
+	return func() {
+		timer.Stop()
+	}
+}
+
+// handlePanic logs and syncs. re-panics unless recoverPanics is set
+func (m *Middleware) handlePanic(ctx context.Context, v interface{}) error {
+	stack := debug.Stack()
+	ent := zapcore.Entry{
+		Level:   m.panicLevel,
+		Time:    time.Now(),
+		Message: "panic in lambda handler",
+		Stack:   string(stack),
+	}
+	if ce := m.logger.Core().Check(ent, nil); ce != nil {
+		ctxValues := m.lc.ContextValues(ctx)
+		fields := make([]zapcore.Field, 0, len(ctxValues)+1)
+		fields = append(fields, zap.Any("panic", v))
+		ce.Write(append(fields, ctxValues...)...)
+	}
+	m.logger.Sync()
+	if !m.recoverPanics {
+		panic(v)
+	}
+	return &PanicError{Value: v, Stack: stack}
+}
@@ -0,0 +1,112 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func panicHandler(ctx context.Context) (string, error) {
+	panic("boom")
+}
+
+func TestMiddlewareRePanic(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, logs := observer.New(zap.InfoLevel)
+	h := NewMiddleware(zap.New(core), New().With(AwsRequestID)).WrapFunc(panicHandler)
+	assert.PanicsWithValue(t, "boom", func() {
+		h.Invoke(lc, []byte("{}"))
+	})
+	if assert.Equal(t, 1, logs.Len()) {
+		e := logs.All()[0]
+		assert.Equal(t, zapcore.ErrorLevel, e.Level)
+		assert.Equal(t, "dummyid", e.ContextMap()["requestId"])
+		assert.Equal(t, "boom", e.ContextMap()["panic"])
+		assert.Contains(t, e.Stack, "panicHandler")
+	}
+}
+
+func TestMiddlewareRecover(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, logs := observer.New(zap.InfoLevel)
+	h := NewMiddleware(zap.New(core), New().With(AwsRequestID), RecoverPanics(true), PanicLevel(zapcore.PanicLevel)).WrapFunc(panicHandler)
+	_, err := h.Invoke(lc, []byte("{}"))
+	if assert.IsType(t, &PanicError{}, err) {
+		assert.Equal(t, "panic: boom", err.Error())
+	}
+	assert.Equal(t, 1, logs.FilterField(zap.String("requestId", "dummyid")).Len())
+	assert.Equal(t, zapcore.PanicLevel, logs.All()[0].Level)
+}
+
+func TestMiddlewarePassThrough(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, logs := observer.New(zap.InfoLevel)
+	h := NewMiddleware(zap.New(core), New().With(AwsRequestID)).WrapFunc(func() (string, error) {
+		return "ok", nil
+	})
+	res, err := h.Invoke(lc, []byte("{}"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"ok"`, string(res))
+	assert.Equal(t, 0, logs.Len())
+}
+
+type syncCounter struct {
+	zapcore.Core
+	syncs int
+}
+
+func (s *syncCounter) Sync() error {
+	s.syncs++
+	return s.Core.Sync()
+}
+
+func TestMiddlewareSync(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, _ := observer.New(zap.InfoLevel)
+	sc := &syncCounter{Core: core}
+	h := NewMiddleware(zap.New(sc), New().With(AwsRequestID)).WrapFunc(func() error {
+		return nil
+	})
+	h.Invoke(lc, []byte("{}"))
+	assert.Equal(t, 1, sc.syncs)
+}
+
+func TestMiddlewareTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(lambdacontext.NewContext(context.Background(), lc), 50*time.Millisecond)
+	defer cancel()
+	core, logs := observer.New(zap.InfoLevel)
+	h := NewMiddleware(zap.New(core), New().With(AwsRequestID), TimeoutMargin(40*time.Millisecond)).WrapFunc(func() error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+	h.Invoke(ctx, []byte("{}"))
+	timeouts := logs.FilterMessage("timeout imminent").All()
+	if assert.Len(t, timeouts, 1) {
+		assert.Equal(t, zapcore.WarnLevel, timeouts[0].Level)
+		assert.Equal(t, "dummyid", timeouts[0].ContextMap()["requestId"])
+	}
+}
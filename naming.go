@@ -0,0 +1,112 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingConvention for keys
+type NamingConvention int
+
+// Naming conventions
+const (
+	// AsIs keep keys unchanged. The default
+	AsIs NamingConvention = iota
+	// SnakeCase e.g. memory_limit_in_mb
+	SnakeCase
+	// CamelCase e.g. memoryLimitInMb
+	CamelCase
+	// KebabCase e.g. memory-limit-in-mb
+	KebabCase
+	// DottedCase e.g. memory.limit.in.mb
+	DottedCase
+)
+
+// KeyNaming apply a naming convention to DefaultNames, registered names, WithEnv and WithCustom keys.
+// CustomNames are used as they are
+func KeyNaming(c NamingConvention) Option {
+	return optionFunc(func(lc *LambdaLogContext) {
+		lc.naming = c
+	})
+}
+
+// KeyPrefix prepended to DefaultNames, registered names, WithEnv and WithCustom keys. e.g. aws.lambda.
+// CustomNames are used as they are
+func KeyPrefix(p string) Option {
+	return optionFunc(func(lc *LambdaLogContext) {
+		lc.prefix = p
+	})
+}
+
+// Apply the convention to key
+func (c NamingConvention) Apply(key string) string {
+	if c == AsIs {
+		return key
+	}
+	words := splitWords(key)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if c == CamelCase && i > 0 {
+			w = strings.ToUpper(w[:1]) + w[1:]
+		}
+		words[i] = w
+	}
+	switch c {
+	case SnakeCase:
+		return strings.Join(words, "_")
+	case KebabCase:
+		return strings.Join(words, "-")
+	case DottedCase:
+		return strings.Join(words, ".")
+	default:
+		return strings.Join(words, "")
+	}
+}
+
+// splitWords on separators and case changes. e.g. memoryLimitInMB, AWS_REGION, HTTPServer
+func splitWords(key string) []string {
+	var words []string
+	r := []rune(key)
+	start := 0
+	for i := 0; i < len(r); i++ {
+		if r[i] == '_' || r[i] == '-' || r[i] == '.' || r[i] == ' ' {
+			if i > start {
+				words = append(words, string(r[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start {
+			continue
+		}
+		lowerToUpper := unicode.IsUpper(r[i]) && !unicode.IsUpper(r[i-1])
+		acronymEnd := unicode.IsUpper(r[i]) && unicode.IsUpper(r[i-1]) && i+1 < len(r) && unicode.IsLower(r[i+1])
+		if lowerToUpper || acronymEnd {
+			words = append(words, string(r[start:i]))
+			start = i
+		}
+	}
+	if start < len(r) {
+		words = append(words, string(r[start:]))
+	}
+	return words
+}
+
+// keyName the logged key for a default, registered, env or custom name
+func (lc *LambdaLogContext) keyName(name string) string {
+	return lc.prefix + lc.naming.Apply(name)
+}
@@ -0,0 +1,60 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamingConventions(t *testing.T) {
+	tests := []struct {
+		key    string
+		c      NamingConvention
+		expect string
+	}{
+		{"memoryLimitInMB", SnakeCase, "memory_limit_in_mb"},
+		{"memoryLimitInMB", KebabCase, "memory-limit-in-mb"},
+		{"memoryLimitInMB", DottedCase, "memory.limit.in.mb"},
+		{"AWS_REGION", CamelCase, "awsRegion"},
+		{"HTTPServer", SnakeCase, "http_server"},
+		{"cognitoIdentityPoolId", AsIs, "cognitoIdentityPoolId"},
+		{"custom-key.name", CamelCase, "customKeyName"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expect, test.c.Apply(test.key), test.key)
+	}
+}
+
+func TestKeyNaming(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	lc, cf := getContext()
+	defer cf()
+	lf := New(KeyNaming(SnakeCase), KeyPrefix("aws.lambda."), CustomNames(map[LambdaField]string{InvokeFunctionArn: "arn"})).
+		With(AwsRequestID, InvokeFunctionArn, MemoryLimitInMB).
+		WithEnv("SHELL").
+		WithCustom("custom1")
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Equal(t, "dummyid", tw.value["aws.lambda.request_id"])
+	assert.Equal(t, "dummyarn", tw.value["arn"])
+	assert.Equal(t, float64(128), tw.value["aws.lambda.memory_limit_in_mb"])
+	assert.Equal(t, "dummycustom1", tw.value["aws.lambda.custom1"])
+	assert.Contains(t, tw.value["aws.lambda.shell"], "/bin")
+}
@@ -0,0 +1,80 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"sort"
+)
+
+// Order of the fields returned by ContextValues and NonContextValues.
+// Fields are sorted when they are added, not when they are logged.
+type Order struct {
+	alphabetical bool
+	priority     map[string]int
+}
+
+// Orders
+var (
+	// DeclarationOrder the order fields were added with With, WithEnv and WithCustom. The default
+	DeclarationOrder = Order{}
+	// AlphabeticalOrder by key
+	AlphabeticalOrder = Order{alphabetical: true}
+)
+
+// PriorityOrder these keys first, in this order, followed by all other fields in declaration order.
+// A key listed twice keeps its first position
+func PriorityOrder(keys ...string) Order {
+	o := Order{priority: make(map[string]int, len(keys))}
+	for _, k := range keys {
+		if _, ok := o.priority[k]; !ok {
+			o.priority[k] = len(o.priority)
+		}
+	}
+	return o
+}
+
+// FieldOrder of ContextValues and NonContextValues. Default DeclarationOrder
+func FieldOrder(o Order) Option {
+	return optionFunc(func(lc *LambdaLogContext) {
+		lc.order = o
+		lc.sortEntries()
+	})
+}
+
+func (o Order) rank(key string) int {
+	if r, ok := o.priority[key]; ok {
+		return r
+	}
+	return len(o.priority)
+}
+
+func (o Order) less(a, b entry) bool {
+	if o.alphabetical {
+		return a.key < b.key
+	}
+	return o.rank(a.key) < o.rank(b.key)
+}
+
+// sortEntries stable sorts entries and rebuilds fields to match
+func (lc *LambdaLogContext) sortEntries() {
+	if !lc.order.alphabetical && len(lc.order.priority) == 0 {
+		return
+	}
+	entries := append(make([]entry, 0, len(lc.entries)), lc.entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return lc.order.less(entries[i], entries[j])
+	})
+	lc.rebuild(entries)
+}
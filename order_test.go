@@ -0,0 +1,75 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		assert.NoError(t, ioutil.WriteFile(golden, actual, 0644))
+	}
+	expected, err := ioutil.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func TestFieldOrder(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	lc, cf := getContext()
+	defer cf()
+	orders := map[string]Order{
+		"declaration":  DeclarationOrder,
+		"alphabetical": AlphabeticalOrder,
+		"priority":     PriorityOrder("requestId", "custom1", "functionName"),
+		"duplicates":   PriorityOrder("custom1", "custom1", "requestId"),
+	}
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = ""
+	for name, o := range orders {
+		buf := &bytes.Buffer{}
+		logger := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(cfg), zapcore.AddSync(buf), zap.InfoLevel))
+		lf := New(FieldOrder(o), ProcessNonContextFields(false)).
+			WithCustom("custom2", "custom1").
+			WithBasic().
+			With(CognitoIdentityID, MemoryLimitInMB)
+		logger.With(lf.NonContextValues()...).Info("test", lf.ContextValues(lc)...)
+		assertGolden(t, "order_"+name, buf.Bytes())
+	}
+}
+
+func TestFieldOrderAllocs(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	lf := New(FieldOrder(AlphabeticalOrder)).WithAll().WithCustom("custom1")
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() {
+		lf.ContextValues(lc)
+	}))
+}
@@ -0,0 +1,280 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultMaxEntrySize CloudWatch Logs maximum event size (256 KB) less its 26 bytes of per event overhead
+const DefaultMaxEntrySize = 256*1024 - 26
+
+// MinEntrySize smallest limit MaxEntrySize accepts, an entry needs room for its level, time and fields
+const MinEntrySize = 4 * minChunk
+
+// OversizeMode what a size limited core does with an entry larger than the limit
+type OversizeMode int
+
+// Oversize modes
+const (
+	// Truncate the entry and add truncated=true
+	Truncate OversizeMode = iota
+	// Split the entry into chunks with chunk and chunks (total) fields
+	Split
+)
+
+// Keys added to oversize entries. Fields which are not repeated are encoded as a JSON object into DataKey.
+// A split entry's message longer than a quarter of the limit is shortened, the whole message is MessageKey in DataKey
+const (
+	TruncatedKey = "truncated"
+	ChunkKey     = "chunk"
+	ChunksKey    = "chunks"
+	DataKey      = "data"
+	MessageKey   = "message"
+)
+
+// A LimitOption configures a core created by NewLimitCore.
+type LimitOption interface {
+	apply(*limitCore)
+}
+
+type limitOptionFunc func(*limitCore)
+
+func (f limitOptionFunc) apply(c *limitCore) {
+	f(c)
+}
+
+// MaxEntrySize in bytes of an encoded entry. Default DefaultMaxEntrySize, sizes below MinEntrySize are ignored
+func MaxEntrySize(n int) LimitOption {
+	return limitOptionFunc(func(c *limitCore) {
+		if n >= MinEntrySize {
+			c.limit = n
+		}
+	})
+}
+
+// Oversize mode. Default Truncate
+func Oversize(m OversizeMode) LimitOption {
+	return limitOptionFunc(func(c *limitCore) {
+		c.mode = m
+	})
+}
+
+// RepeatKeys fields with these keys are kept on a truncated entry and repeated on every chunk.
+// Fields added with zap.Logger.With are always kept. Use with LambdaLogContext.Keys()
+func RepeatKeys(keys ...string) LimitOption {
+	return limitOptionFunc(func(c *limitCore) {
+		for _, k := range keys {
+			c.repeat[k] = struct{}{}
+		}
+	})
+}
+
+type limitCore struct {
+	zapcore.LevelEnabler
+	enc    zapcore.Encoder
+	out    zapcore.WriteSyncer
+	limit  int
+	mode   OversizeMode
+	repeat map[string]struct{}
+}
+
+// NewLimitCore Create a core like zapcore.NewCore which truncates or splits entries larger than MaxEntrySize
+func NewLimitCore(enc zapcore.Encoder, ws zapcore.WriteSyncer, enab zapcore.LevelEnabler, options ...LimitOption) zapcore.Core {
+	c := &limitCore{
+		LevelEnabler: enab,
+		enc:          enc,
+		out:          ws,
+		limit:        DefaultMaxEntrySize,
+		repeat:       make(map[string]struct{}),
+	}
+	for _, o := range options {
+		o.apply(c)
+	}
+	return c
+}
+
+func (c *limitCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *limitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *limitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	if buf.Len() <= c.limit {
+		_, err = c.out.Write(buf.Bytes())
+		buf.Free()
+	} else {
+		buf.Free()
+		err = c.writeOversize(ent, fields)
+	}
+	if err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		// Since we may be crashing the program, sync the output.
+		c.Sync()
+	}
+	return nil
+}
+
+func (c *limitCore) Sync() error {
+	return c.out.Sync()
+}
+
+var dataEncoder = zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+
+func (c *limitCore) writeOversize(ent zapcore.Entry, fields []zapcore.Field) error {
+	keep := make([]zapcore.Field, 0, len(fields)+4)
+	rest := make([]zapcore.Field, 0, len(fields)+1)
+	for _, f := range fields {
+		if _, ok := c.repeat[f.Key]; ok {
+			keep = append(keep, f)
+		} else {
+			rest = append(rest, f)
+		}
+	}
+	if ent.Stack != "" {
+		rest = append(rest, zap.String("stacktrace", ent.Stack))
+		ent.Stack = ""
+	}
+	truncated := false
+	if max := c.limit / 4; len(ent.Message) > max {
+		if c.mode == Split {
+			// the whole message goes into the chunks so they reassemble to the original
+			rest = append(rest, zap.String(MessageKey, ent.Message))
+		} else {
+			keep = append(keep, zap.Bool(TruncatedKey, true))
+			truncated = true
+		}
+		ent.Message = splitEscaped(ent.Message, max)[0]
+	}
+	buf, err := dataEncoder.EncodeEntry(zapcore.Entry{}, rest)
+	if err != nil {
+		return err
+	}
+	data := strings.TrimSuffix(buf.String(), "\n")
+	buf.Free()
+
+	if c.mode == Split {
+		return c.writeChunks(ent, keep, data)
+	}
+	if !truncated {
+		keep = append(keep, zap.Bool(TruncatedKey, true))
+	}
+	data = splitEscaped(data, c.available(ent, append(keep, zap.String(DataKey, ""))))[0]
+	return c.write(ent, append(keep, zap.String(DataKey, data)))
+}
+
+func (c *limitCore) writeChunks(ent zapcore.Entry, keep []zapcore.Field, data string) error {
+	// placeholders as wide as the largest possible chunk count
+	placeholder := len(data)
+	n := len(keep)
+	fields := append(keep, zap.Int(ChunkKey, placeholder), zap.Int(ChunksKey, placeholder), zap.String(DataKey, ""))
+	chunks := splitEscaped(data, c.available(ent, fields))
+	fields[n+1] = zap.Int(ChunksKey, len(chunks))
+	for i, chunk := range chunks {
+		fields[n] = zap.Int(ChunkKey, i+1)
+		fields[n+2] = zap.String(DataKey, chunk)
+		if err := c.write(ent, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// available bytes for the data value once fields are encoded
+func (c *limitCore) available(ent zapcore.Entry, fields []zapcore.Field) int {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return minChunk
+	}
+	avail := c.limit - buf.Len()
+	buf.Free()
+	if avail < minChunk {
+		return minChunk
+	}
+	return avail
+}
+
+// minChunk keeps splitting going when the repeated fields alone are close to the limit
+const minChunk = 64
+
+func (c *limitCore) write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	_, err = c.out.Write(buf.Bytes())
+	buf.Free()
+	return err
+}
+
+// splitEscaped splits s into pieces whose JSON escaped length is at most max, never inside a rune.
+// There is always at least one piece.
+func splitEscaped(s string, max int) []string {
+	if max <= 0 {
+		max = 1
+	}
+	pieces := make([]string, 0, len(s)/max+1)
+	start, size := 0, 0
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		cost := escapedLen(s[i], r, n)
+		if size+cost > max && i > start {
+			pieces = append(pieces, s[start:i])
+			start, size = i, 0
+		}
+		size += cost
+		i += n
+	}
+	return append(pieces, s[start:])
+}
+
+// escapedLen the length of a rune escaped by zap's JSON encoder
+func escapedLen(b byte, r rune, n int) int {
+	if b < utf8.RuneSelf {
+		switch {
+		case b >= 0x20 && b != '\\' && b != '"':
+			return 1
+		case b == '\\', b == '"', b == '\n', b == '\r', b == '\t':
+			return 2
+		default:
+			return 6
+		}
+	}
+	if r == utf8.RuneError && n == 1 {
+		return 6
+	}
+	return n
+}
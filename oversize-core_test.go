@@ -0,0 +1,112 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func limitLogger(options ...LimitOption) (*zap.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	core := NewLimitCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.InfoLevel, options...)
+	return zap.New(core), buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer, limit int) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		assert.True(t, len(line)+1 <= limit, "line is %d bytes", len(line)+1)
+		e := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestLimitCoreSmall(t *testing.T) {
+	logger, buf := limitLogger(MaxEntrySize(512))
+	logger.Info("test", zap.String("requestId", "dummyid"))
+	entries := decodeLines(t, buf, 512)
+	assert.Len(t, entries, 1)
+	assert.Nil(t, entries[0][TruncatedKey])
+}
+
+func TestLimitCoreTruncate(t *testing.T) {
+	lf := New().With(AwsRequestID)
+	logger, buf := limitLogger(MaxEntrySize(512), RepeatKeys(lf.Keys()...))
+	logger.Info("test", zap.String("requestId", "dummyid"), zap.String("payload", strings.Repeat("\"é", 500)))
+	entries := decodeLines(t, buf, 512)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, true, entries[0][TruncatedKey])
+		assert.Equal(t, "dummyid", entries[0]["requestId"])
+		assert.Equal(t, "test", entries[0]["msg"])
+		assert.Contains(t, entries[0][DataKey], `{"payload":"\"é`)
+	}
+}
+
+func TestLimitCoreSplit(t *testing.T) {
+	logger, buf := limitLogger(MaxEntrySize(512), Oversize(Split), RepeatKeys("requestId"))
+	payload := strings.Repeat("0123456789\n", 200)
+	logger.Info("test", zap.String("requestId", "dummyid"), zap.String("payload", payload))
+	entries := decodeLines(t, buf, 512)
+	data := ""
+	for i, e := range entries {
+		assert.Equal(t, "dummyid", e["requestId"])
+		assert.Equal(t, float64(i+1), e[ChunkKey])
+		assert.Equal(t, float64(len(entries)), e[ChunksKey])
+		data += e[DataKey].(string)
+	}
+	assert.True(t, len(entries) > 1)
+	v := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal([]byte(data), &v))
+	assert.Equal(t, payload, v["payload"])
+}
+
+func TestLimitCoreSplitMessage(t *testing.T) {
+	logger, buf := limitLogger(MaxEntrySize(1000), Oversize(Split), RepeatKeys("requestId"))
+	msg := strings.Repeat("message é\"", 60)
+	payload := strings.Repeat("0123456789", 100)
+	logger.Info(msg, zap.String("requestId", "dummyid"), zap.String("payload", payload))
+	entries := decodeLines(t, buf, 1000)
+	assert.True(t, len(entries) > 1)
+	data := ""
+	for _, e := range entries {
+		assert.Nil(t, e[TruncatedKey])
+		assert.True(t, strings.HasPrefix(msg, e["msg"].(string)))
+		data += e[DataKey].(string)
+	}
+	v := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal([]byte(data), &v))
+	assert.Equal(t, map[string]interface{}{MessageKey: msg, "payload": payload}, v)
+}
+
+func TestLimitCoreInvalidSize(t *testing.T) {
+	for _, n := range []int{-1, 0, 3, MinEntrySize - 1} {
+		logger, buf := limitLogger(MaxEntrySize(n), Oversize(Split))
+		assert.NotPanics(t, func() {
+			logger.Info(strings.Repeat("m", 1024), zap.String("payload", strings.Repeat("p", 1024)))
+		})
+		entries := decodeLines(t, buf, DefaultMaxEntrySize)
+		assert.Len(t, entries, 1, "size %d", n)
+	}
+	assert.Len(t, splitEscaped("abc", 0), 3)
+}
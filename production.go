@@ -0,0 +1,141 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// functionNameEnv is only set when running in lambda
+const functionNameEnv = "AWS_LAMBDA_FUNCTION_NAME"
+
+// ProductionOption configures NewProduction
+type ProductionOption interface {
+	apply(*production)
+}
+
+type productionOptionFunc func(*production)
+
+func (f productionOptionFunc) apply(p *production) {
+	f(p)
+}
+
+type production struct {
+	config      *Config
+	options     []Option
+	zapOptions  []zap.Option
+	epochMillis bool
+	local       *bool
+	out         zapcore.WriteSyncer
+}
+
+// FromConfig use c instead of ConfigFromEnv
+func FromConfig(c Config) ProductionOption {
+	return productionOptionFunc(func(p *production) {
+		p.config = &c
+	})
+}
+
+// ContextOptions for New, applied before the config's
+func ContextOptions(opts ...Option) ProductionOption {
+	return productionOptionFunc(func(p *production) {
+		p.options = append(p.options, opts...)
+	})
+}
+
+// ZapOptions added to the logger
+func ZapOptions(opts ...zap.Option) ProductionOption {
+	return productionOptionFunc(func(p *production) {
+		p.zapOptions = append(p.zapOptions, opts...)
+	})
+}
+
+// EpochMillis timestamps instead of RFC3339Nano
+func EpochMillis(b bool) ProductionOption {
+	return productionOptionFunc(func(p *production) {
+		p.epochMillis = b
+	})
+}
+
+// Local force the colorized console encoder on or off. By default it is on when AWS_LAMBDA_FUNCTION_NAME is unset
+func Local(b bool) ProductionOption {
+	return productionOptionFunc(func(p *production) {
+		p.local = &b
+	})
+}
+
+// Output write to ws instead of stdout
+func Output(ws zapcore.WriteSyncer) ProductionOption {
+	return productionOptionFunc(func(p *production) {
+		p.out = ws
+	})
+}
+
+// RFC3339NanoTimeEncoder serializes a time.Time to an RFC3339Nano UTC string
+func RFC3339NanoTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(t.UTC().Format(time.RFC3339Nano))
+}
+
+// NewProduction a JSON logger for CloudWatch with caller info and NonContextValues already attached.
+// Fields and options come from ConfigFromEnv, BasicFields when LAMBDAZAP_FIELDS is unset.
+// Log lambdazapper.ContextValues(ctx) in the handler as usual.
+func NewProduction(opts ...ProductionOption) (*zap.Logger, *LambdaLogContext, error) {
+	p := &production{out: zapcore.Lock(os.Stdout)}
+	for _, o := range opts {
+		o.apply(p)
+	}
+	if p.config == nil {
+		c, err := ConfigFromEnv()
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(c.Fields) == 0 {
+			c.Fields = []string{"basic"}
+		}
+		p.config = &c
+	}
+	level, err := p.config.ZapLevel()
+	if err != nil {
+		return nil, nil, err
+	}
+	// NonContextValues are attached to the logger, not repeated by ContextValues
+	lc, err := p.config.Build(append(p.options, ProcessNonContextFields(false))...)
+	if err != nil {
+		return nil, nil, err
+	}
+	local := os.Getenv(functionNameEnv) == ""
+	if p.local != nil {
+		local = *p.local
+	}
+	var enc zapcore.Encoder
+	if local {
+		cfg := zap.NewDevelopmentEncoderConfig()
+		cfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		enc = zapcore.NewConsoleEncoder(cfg)
+	} else {
+		cfg := zap.NewProductionEncoderConfig()
+		cfg.EncodeTime = RFC3339NanoTimeEncoder
+		if p.epochMillis {
+			cfg.EncodeTime = zapcore.EpochMillisTimeEncoder
+		}
+		enc = zapcore.NewJSONEncoder(cfg)
+	}
+	options := append([]zap.Option{zap.AddCaller(), zap.Fields(lc.NonContextValues()...)}, p.zapOptions...)
+	return zap.New(zapcore.NewCore(enc, p.out, level), options...), lc, nil
+}
@@ -0,0 +1,89 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewProduction(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	ctx, cf := getContext()
+	defer cf()
+	buf := &bytes.Buffer{}
+	logger, lc, err := NewProduction(Local(false), Output(zapcore.AddSync(buf)))
+	assert.Nil(t, err)
+	logger.Info("test", lc.ContextValues(ctx)...)
+	logger.Debug("not logged")
+
+	var value map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &value))
+	assert.Equal(t, "dummyid", value["requestId"])
+	assert.Equal(t, "dummyfunction", value["functionName"])
+	assert.Contains(t, value["caller"], "production_test.go")
+	_, err = time.Parse(time.RFC3339Nano, value["ts"].(string))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(buf.String(), `"functionName"`))
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestNewProductionEnv(t *testing.T) {
+	os.Setenv("ZAP_TEST", "dummyenv")
+	defer os.Unsetenv("ZAP_TEST")
+	ctx, cf := getContext()
+	defer cf()
+	buf := &bytes.Buffer{}
+	logger, lc, err := NewProduction(FromConfig(Config{Fields: []string{"requestId"}, Env: []string{"ZAP_TEST"}}),
+		Local(false), Output(zapcore.AddSync(buf)))
+	assert.Nil(t, err)
+	logger.Info("test", lc.ContextValues(ctx)...)
+	assert.Equal(t, 1, strings.Count(buf.String(), `"ZAP_TEST":"dummyenv"`), buf.String())
+	assert.Equal(t, 1, strings.Count(buf.String(), `"requestId"`))
+}
+
+func TestNewProductionOptions(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, _, err := NewProduction(
+		FromConfig(Config{Fields: []string{"requestId"}, Level: "debug"}),
+		EpochMillis(true), Local(false), Output(zapcore.AddSync(buf)))
+	assert.Nil(t, err)
+	logger.Debug("test")
+	var value map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &value))
+	assert.IsType(t, float64(0), value["ts"])
+
+	buf.Reset()
+	logger, _, err = NewProduction(FromConfig(Config{}), Local(true), Output(zapcore.AddSync(buf)))
+	assert.Nil(t, err)
+	logger.Info("test")
+	assert.Contains(t, buf.String(), "\x1b[34mINFO\x1b[0m")
+
+	_, _, err = NewProduction(FromConfig(Config{Level: "loud"}))
+	assert.Error(t, err)
+
+	_, _, err = NewProduction(FromConfig(Config{Fields: []string{"requestId"}, Env: []string{"requestId"}}))
+	assert.IsType(t, &CollisionError{}, err)
+}
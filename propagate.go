@@ -0,0 +1,115 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// Names of the values added to outgoing messages
+const (
+	CorrelationIDAttribute = "correlationId"
+	TraceparentAttribute   = TraceparentHeader
+	RequestIDAttribute     = "requestId"
+	FunctionNameAttribute  = "functionName"
+	// EventBridgeMetadataKey the detail key of the EventBridge metadata block
+	EventBridgeMetadataKey = "metadata"
+	// MaxMessageAttributes SQS and SNS allow per message
+	MaxMessageAttributes = 10
+)
+
+type propagationValue struct {
+	name  string
+	value string
+}
+
+// propagation the values of ctx, most important first
+func propagation(ctx context.Context) []propagationValue {
+	values := make([]propagationValue, 0, 4)
+	add := func(name, value string) {
+		if value != "" {
+			values = append(values, propagationValue{name: name, value: value})
+		}
+	}
+	add(CorrelationIDAttribute, CorrelationIDFromContext(ctx))
+	if sc, ok := TraceContext(ctx); ok {
+		add(TraceparentAttribute, formatTraceparent(sc))
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		add(RequestIDAttribute, lc.AwsRequestID)
+	}
+	add(FunctionNameAttribute, lambdacontext.FunctionName)
+	return values
+}
+
+// PropagationValues the correlation id, traceparent, request id and function name of the invocation.
+// Use them with any SDK's message types
+func PropagationValues(ctx context.Context) map[string]string {
+	values := make(map[string]string)
+	for _, v := range propagation(ctx) {
+		values[v.name] = v.value
+	}
+	return values
+}
+
+// SQSMessageAttributes add the PropagationValues as String attributes, {"DataType": "String", "StringValue": value},
+// to attrs, a new map when nil. Existing attributes are kept, and no more than MaxMessageAttributes are added.
+// MessageAttributeSource reads them back on the consumer side
+func SQSMessageAttributes(ctx context.Context, attrs map[string]interface{}) map[string]interface{} {
+	if attrs == nil {
+		attrs = make(map[string]interface{})
+	}
+	for _, v := range propagation(ctx) {
+		if len(attrs) >= MaxMessageAttributes {
+			break
+		}
+		if _, ok := attrs[v.name]; !ok {
+			attrs[v.name] = map[string]interface{}{"DataType": "String", "StringValue": v.value}
+		}
+	}
+	return attrs
+}
+
+// SNSMessageAttributes like SQSMessageAttributes, SNS uses the same attribute shape
+func SNSMessageAttributes(ctx context.Context, attrs map[string]interface{}) map[string]interface{} {
+	return SQSMessageAttributes(ctx, attrs)
+}
+
+// EventBridgeDetail add the PropagationValues to the metadata block of an event's detail, a new map when nil.
+// Existing metadata values are kept, a metadata value which is not an object is not changed.
+// EventBridgeSource("metadata.correlationId") reads them back on the consumer side
+func EventBridgeDetail(ctx context.Context, detail map[string]interface{}) map[string]interface{} {
+	if detail == nil {
+		detail = make(map[string]interface{})
+	}
+	existing, found := detail[EventBridgeMetadataKey]
+	metadata, ok := existing.(map[string]interface{})
+	if found && !ok {
+		// not ours, leave it alone
+		return detail
+	}
+	if !ok {
+		metadata = make(map[string]interface{})
+		detail[EventBridgeMetadataKey] = metadata
+	}
+	for _, v := range propagation(ctx) {
+		if _, ok := metadata[v.name]; !ok {
+			metadata[v.name] = v.value
+		}
+	}
+	return detail
+}
@@ -0,0 +1,83 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+)
+
+func propagationContext() context.Context {
+	ctx := lambdacontext.NewContext(context.Background(), lc)
+	return WithTraceparent(WithCorrelationID(ctx, "corr"), testTraceparent)
+}
+
+func TestPropagationValues(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	assert.Equal(t, map[string]string{
+		CorrelationIDAttribute: "corr",
+		TraceparentAttribute:   testTraceparent,
+		RequestIDAttribute:     "dummyid",
+		FunctionNameAttribute:  "dummyfunction",
+	}, PropagationValues(propagationContext()))
+	assert.Equal(t, map[string]string{FunctionNameAttribute: "dummyfunction"}, PropagationValues(context.Background()))
+}
+
+func TestSQSMessageAttributes(t *testing.T) {
+	ctx := propagationContext()
+	attrs := SQSMessageAttributes(ctx, map[string]interface{}{CorrelationIDAttribute: "mine"})
+	assert.Equal(t, "mine", attrs[CorrelationIDAttribute])
+	assert.Equal(t, map[string]interface{}{"DataType": "String", "StringValue": testTraceparent}, attrs[TraceparentAttribute])
+	assert.Equal(t, map[string]interface{}{"DataType": "String", "StringValue": "dummyid"}, SNSMessageAttributes(ctx, nil)[RequestIDAttribute])
+
+	full := make(map[string]interface{})
+	for i := 0; i < MaxMessageAttributes-1; i++ {
+		full[strconv.Itoa(i)] = i
+	}
+	full = SQSMessageAttributes(ctx, full)
+	assert.Len(t, full, MaxMessageAttributes)
+	assert.Contains(t, full, CorrelationIDAttribute)
+
+	// the consumer side reads them back
+	event := map[string]interface{}{"Records": []interface{}{map[string]interface{}{"messageAttributes": map[string]interface{}{
+		CorrelationIDAttribute: map[string]interface{}{"stringValue": "corr"},
+		TraceparentAttribute:   map[string]interface{}{"stringValue": testTraceparent},
+	}}}}
+	assert.Equal(t, "corr", firstSource(ctx, event, DefaultCorrelationSources))
+	assert.Equal(t, testTraceparent, firstSource(ctx, event, DefaultTraceparentSources))
+}
+
+func TestEventBridgeDetail(t *testing.T) {
+	ctx := propagationContext()
+	detail := EventBridgeDetail(ctx, map[string]interface{}{"id": 1, "metadata": map[string]interface{}{"requestId": "mine"}})
+	b, err := json.Marshal(map[string]interface{}{"detail-type": "created", "detail": detail})
+	assert.NoError(t, err)
+	event := decodeEvent(b)
+	assert.Equal(t, "corr", firstSource(ctx, event, DefaultCorrelationSources))
+	assert.Equal(t, testTraceparent, firstSource(ctx, event, DefaultTraceparentSources))
+	assert.Equal(t, "mine", lookupString(event, "detail", "metadata", "requestId"))
+	assert.Equal(t, float64(1), event["detail"].(map[string]interface{})["id"])
+
+	assert.Equal(t, "theirs", EventBridgeDetail(ctx, map[string]interface{}{"metadata": "theirs"})["metadata"])
+	assert.Contains(t, EventBridgeDetail(ctx, nil), "metadata")
+}
@@ -0,0 +1,217 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Common patterns for RedactPatterns
+var (
+	CardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	EmailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// Defaults of a Redactor
+const (
+	DefaultMask         = "****"
+	DefaultMaxDepth     = 10
+	DefaultMaxBytes     = 8 * 1024
+	maxDepthPlaceholder = "[max depth]"
+)
+
+// A RedactOption configures a Redactor.
+type RedactOption interface {
+	apply(*Redactor)
+}
+
+type redactOptionFunc func(*Redactor)
+
+func (f redactOptionFunc) apply(r *Redactor) {
+	f(r)
+}
+
+// RedactKeys mask the value of these keys (case insensitive) at any depth
+func RedactKeys(keys ...string) RedactOption {
+	return redactOptionFunc(func(r *Redactor) {
+		for _, k := range keys {
+			r.keys[strings.ToLower(k)] = true
+		}
+	})
+}
+
+// RedactPaths mask the value at these dotted paths. * matches any key or array index. e.g. records.*.body.password
+func RedactPaths(paths ...string) RedactOption {
+	return redactOptionFunc(func(r *Redactor) {
+		for _, p := range paths {
+			r.paths = append(r.paths, strings.Split(p, "."))
+		}
+	})
+}
+
+// RedactPatterns mask matches of these expressions in every string value
+func RedactPatterns(patterns ...*regexp.Regexp) RedactOption {
+	return redactOptionFunc(func(r *Redactor) {
+		r.patterns = append(r.patterns, patterns...)
+	})
+}
+
+// Mask replaces redacted values. Default DefaultMask
+func Mask(m string) RedactOption {
+	return redactOptionFunc(func(r *Redactor) {
+		r.mask = m
+	})
+}
+
+// MaxDepth of nested objects and arrays. Deeper values are replaced. Default DefaultMaxDepth
+func MaxDepth(n int) RedactOption {
+	return redactOptionFunc(func(r *Redactor) {
+		r.maxDepth = n
+	})
+}
+
+// MaxBytes of a redacted payload. Larger payloads are logged as a truncated string. Default DefaultMaxBytes
+func MaxBytes(n int) RedactOption {
+	return redactOptionFunc(func(r *Redactor) {
+		r.maxBytes = n
+	})
+}
+
+// Redactor masks sensitive values of JSON payloads before they are logged
+type Redactor struct {
+	keys     map[string]bool
+	paths    [][]string
+	patterns []*regexp.Regexp
+	mask     string
+	maxDepth int
+	maxBytes int
+}
+
+// NewRedactor Create a Redactor
+func NewRedactor(options ...RedactOption) *Redactor {
+	r := &Redactor{keys: make(map[string]bool), mask: DefaultMask, maxDepth: DefaultMaxDepth, maxBytes: DefaultMaxBytes}
+	for _, o := range options {
+		o.apply(r)
+	}
+	return r
+}
+
+// Redact a payload. A payload which is not JSON is treated as a string
+func (r *Redactor) Redact(payload []byte) []byte {
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return []byte(r.maskString(string(payload)))
+	}
+	b, err := json.Marshal(r.walk(v, nil, 0))
+	if err != nil {
+		return []byte(r.maskString(string(payload)))
+	}
+	return b
+}
+
+// Fields the redacted payload under key. A JSON payload is logged as JSON unless it is larger than MaxBytes,
+// then it is logged as a string truncated to MaxBytes with <key>Truncated=true
+func (r *Redactor) Fields(key string, payload []byte) []zapcore.Field {
+	redacted := r.Redact(payload)
+	if r.maxBytes > 0 && len(redacted) > r.maxBytes {
+		return []zapcore.Field{
+			zap.ByteString(key, truncateUTF8(redacted, r.maxBytes)),
+			zap.Bool(key+"Truncated", true),
+		}
+	}
+	if json.Valid(redacted) {
+		return []zapcore.Field{zap.Reflect(key, json.RawMessage(redacted))}
+	}
+	return []zapcore.Field{zap.ByteString(key, redacted)}
+}
+
+// truncateUTF8 to at most n bytes without splitting a rune
+func truncateUTF8(b []byte, n int) []byte {
+	if len(b) <= n {
+		return b
+	}
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return b[:n]
+}
+
+func (r *Redactor) walk(v interface{}, path []string, depth int) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if r.maxDepth > 0 && depth >= r.maxDepth {
+			return maxDepthPlaceholder
+		}
+		for k, val := range t {
+			p := append(path[:len(path):len(path)], k)
+			if r.keys[strings.ToLower(k)] || r.matchPath(p) {
+				t[k] = r.mask
+				continue
+			}
+			t[k] = r.walk(val, p, depth+1)
+		}
+	case []interface{}:
+		if r.maxDepth > 0 && depth >= r.maxDepth {
+			return maxDepthPlaceholder
+		}
+		for i, val := range t {
+			p := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if r.matchPath(p) {
+				t[i] = r.mask
+				continue
+			}
+			t[i] = r.walk(val, p, depth+1)
+		}
+	case string:
+		return r.maskString(t)
+	}
+	return v
+}
+
+func (r *Redactor) matchPath(path []string) bool {
+	for _, p := range r.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		match := true
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) maskString(s string) string {
+	for _, p := range r.patterns {
+		s = p.ReplaceAllLiteralString(s, r.mask)
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var event = []byte(`{
+	"user": {"email": "someone@example.com", "Password": "secret"},
+	"records": [{"card": "4111 1111 1111 1111", "amount": 10.50}, {"token": "abc"}],
+	"deep": {"a": {"b": {"c": 1}}}
+}`)
+
+func TestRedact(t *testing.T) {
+	r := NewRedactor(
+		RedactKeys("password"),
+		RedactPaths("records.*.token"),
+		RedactPatterns(CardNumberPattern, EmailPattern),
+		MaxDepth(3),
+	)
+	assert.JSONEq(t, `{
+		"user": {"email": "****", "Password": "****"},
+		"records": [{"card": "****", "amount": 10.50}, {"token": "****"}],
+		"deep": {"a": {"b": "[max depth]"}}
+	}`, string(r.Redact(event)))
+	assert.Equal(t, "mail ****", string(r.Redact([]byte("mail someone@example.com"))))
+}
+
+func TestRedactMaxBytes(t *testing.T) {
+	r := NewRedactor(MaxBytes(10))
+	fields := r.Fields("event", []byte(`{"big":"`+strings.Repeat("x", 100)+`"}`))
+	if assert.Len(t, fields, 2) {
+		assert.Equal(t, []byte(`{"big":"xx`), fields[0].Interface)
+		assert.Equal(t, "eventTruncated", fields[1].Key)
+	}
+}
+
+func TestMiddlewarePayloads(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, logs := observer.New(zap.InfoLevel)
+	h := NewMiddleware(zap.New(core), New().With(AwsRequestID), LogPayloads(NewRedactor(RedactKeys("password")))).
+		WrapFunc(func(in map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"password": "secret", "ok": true}, nil
+		})
+	_, err := h.Invoke(lc, event)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logs.FilterField(zap.String("requestId", "dummyid")).Len())
+	response := logs.FilterMessage("lambda response").All()
+	if assert.Len(t, response, 1) {
+		assert.JSONEq(t, `{"password": "****", "ok": true}`, string(response[0].ContextMap()["response"].(json.RawMessage)))
+	}
+}
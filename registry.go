@@ -0,0 +1,157 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldKind how and when a field gets its value
+type FieldKind int
+
+// Field kinds
+const (
+	// InvocationKind resolved from the lambda context on every invocation
+	InvocationKind FieldKind = iota
+	// StaticKind resolved once, e.g. lambdacontext.FunctionName
+	StaticKind
+	// EnvKind resolved once with os.Getenv. See WithEnv
+	EnvKind
+	// ClientCustomKind resolved from ClientContext.Custom on every invocation. See WithCustom
+	ClientCustomKind
+	// UserKind registered with RegisterField and resolved on every invocation
+	UserKind
+)
+
+// Resolver returns the field for key. ctx and lc are the invocation's contexts,
+// for StaticKind fields they are context.Background() and an empty LambdaContext.
+type Resolver func(ctx context.Context, lc *lambdacontext.LambdaContext, key string) zapcore.Field
+
+// FieldDef describes a field which can be passed to With
+type FieldDef struct {
+	// Name is the default key
+	Name    string
+	Kind    FieldKind
+	Resolve Resolver
+}
+
+const invalidField LambdaField = -1
+
+type fieldRegistry struct {
+	sync.RWMutex
+	defs        []FieldDef
+	byCanonical map[string]LambdaField
+}
+
+// canonicalName the name without case and separators, traceId, trace_id and TRACE-ID are traceid
+func canonicalName(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), ""))
+}
+
+func (r *fieldRegistry) add(def FieldDef) LambdaField {
+	f := LambdaField(len(r.defs))
+	r.defs = append(r.defs, def)
+	r.byCanonical[canonicalName(def.Name)] = f
+	return f
+}
+
+// registry is initialized as a var, not in init, so fields can be registered by other package level vars
+var registry = newFieldRegistry()
+
+func newFieldRegistry() *fieldRegistry {
+	r := &fieldRegistry{byCanonical: make(map[string]LambdaField)}
+	for f := AwsRequestID; f < END; f++ {
+		def := FieldDef{Name: DefaultNames[f], Kind: InvocationKind}
+		if f >= FunctionName {
+			def.Kind = StaticKind
+			def.Resolve = staticResolver(f)
+		}
+		r.add(def)
+	}
+	return r
+}
+
+func staticResolver(f LambdaField) Resolver {
+	return func(ctx context.Context, lc *lambdacontext.LambdaContext, key string) zapcore.Field {
+		if f == MemoryLimitInMB {
+			// Speical case : Memory is an int
+			return zap.Int(key, lambdacontext.MemoryLimitInMB)
+		}
+		return zap.String(key, Extract(lc, f))
+	}
+}
+
+// RegisterField add a field of StaticKind or UserKind. Use the returned LambdaField with With
+func RegisterField(def FieldDef) (LambdaField, error) {
+	if def.Name == "" {
+		return invalidField, fmt.Errorf("lambdazap: field has no name")
+	}
+	if def.Resolve == nil {
+		return invalidField, fmt.Errorf("lambdazap: field %s has no resolver", def.Name)
+	}
+	if def.Kind != StaticKind && def.Kind != UserKind {
+		return invalidField, fmt.Errorf("lambdazap: field %s must be StaticKind or UserKind", def.Name)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if f, ok := registry.byCanonical[canonicalName(def.Name)]; ok {
+		return invalidField, fmt.Errorf("lambdazap: field %s is already registered as %s", def.Name, registry.defs[f].Name)
+	}
+	return registry.add(def), nil
+}
+
+// MustRegisterField like RegisterField but panics on error
+func MustRegisterField(def FieldDef) LambdaField {
+	f, err := RegisterField(def)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// LookupField by its default name, e.g. requestId, in any naming convention, e.g. request_id
+func LookupField(name string) (LambdaField, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	f, ok := registry.byCanonical[canonicalName(name)]
+	return f, ok
+}
+
+// FieldNames the default names of the built in and registered fields
+func FieldNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, len(registry.defs))
+	for i, def := range registry.defs {
+		names[i] = def.Name
+	}
+	return names
+}
+
+func fieldDef(f LambdaField) (FieldDef, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	if f < 0 || int(f) >= len(registry.defs) {
+		return FieldDef{}, false
+	}
+	return registry.defs[f], true
+}
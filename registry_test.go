@@ -0,0 +1,100 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	testRegion = MustRegisterField(FieldDef{
+		Name: "testRegion",
+		Kind: StaticKind,
+		Resolve: func(ctx context.Context, lc *lambdacontext.LambdaContext, key string) zapcore.Field {
+			return zap.String(key, "us-east-1")
+		},
+	})
+	testArnLength = MustRegisterField(FieldDef{
+		Name: "testArnLength",
+		Kind: UserKind,
+		Resolve: func(ctx context.Context, lc *lambdacontext.LambdaContext, key string) zapcore.Field {
+			return zap.Int(key, len(lc.InvokedFunctionArn))
+		},
+	})
+)
+
+func TestRegisterField(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	lf := New(ProcessNonContextFields(false), CustomNames(map[LambdaField]string{testArnLength: "arnLength"})).
+		With(AwsRequestID, testRegion, testArnLength)
+	logger, tw := getLogger()
+	logger = logger.With(lf.NonContextValues()...)
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Equal(t, "us-east-1", tw.value["testRegion"])
+	assert.Equal(t, float64(len("dummyarn")), tw.value["arnLength"])
+	assert.Equal(t, "dummyid", tw.value["requestId"])
+
+	f, ok := LookupField("testRegion")
+	assert.True(t, ok)
+	assert.Equal(t, testRegion, f)
+}
+
+func TestRegisterFieldErrors(t *testing.T) {
+	resolve := func(ctx context.Context, lc *lambdacontext.LambdaContext, key string) zapcore.Field {
+		return zap.Skip()
+	}
+	_, err := RegisterField(FieldDef{Name: "requestId", Kind: UserKind, Resolve: resolve})
+	assert.Error(t, err)
+	_, err = RegisterField(FieldDef{Name: "request_id", Kind: UserKind, Resolve: resolve})
+	assert.Contains(t, err.Error(), "already registered as requestId")
+	_, err = RegisterField(FieldDef{Name: "noResolver", Kind: UserKind})
+	assert.Error(t, err)
+	_, err = RegisterField(FieldDef{Name: "env", Kind: EnvKind, Resolve: resolve})
+	assert.Error(t, err)
+}
+
+func TestLookupField(t *testing.T) {
+	for _, name := range []string{"memoryLimitInMB", "memory_limit_in_mb", "MemoryLimitInMB", "memory.limit.in.mb"} {
+		f, ok := LookupField(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, MemoryLimitInMB, f, name)
+	}
+	_, ok := LookupField("memory")
+	assert.False(t, ok)
+	assert.Contains(t, FieldNames(), "coldStart")
+}
+
+func TestManyFields(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	lf := New()
+	for i := 0; i < 150; i++ {
+		lf.WithEnv(fmt.Sprintf("ENV_%d", i)).WithCustom(fmt.Sprintf("custom%d", i))
+	}
+	lf.With(AwsRequestID)
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Len(t, tw.value, 302)
+	assert.Equal(t, "dummycustom1", tw.value["custom1"])
+	assert.Equal(t, "dummyid", tw.value["requestId"])
+}
@@ -0,0 +1,261 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler adds the lambda fields of the context passed to slog.InfoContext etc. to every record.
+// The fields are top level, they are added before the attrs and groups of WithAttrs and WithGroup.
+// With groups or attrs the next handler is rebuilt when the fields change, usually once per invocation
+type SlogHandler struct {
+	base  slog.Handler
+	next  slog.Handler
+	ops   []slogOp
+	lc    *LambdaLogContext
+	cache atomic.Pointer[slogCache]
+}
+
+// slogCache the handler rebuilt with the lambda fields of the last record, they only change between invocations
+type slogCache struct {
+	fields  []zapcore.Field
+	handler slog.Handler
+}
+
+// sameFields compares fields without an Interface value, others are never the same
+func sameFields(a, b []zapcore.Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Interface != nil || b[i].Interface != nil || a[i].Key != b[i].Key || a[i].Type != b[i].Type ||
+			a[i].Integer != b[i].Integer || a[i].String != b[i].String {
+			return false
+		}
+	}
+	return true
+}
+
+// slogOp a WithAttrs (group empty) or WithGroup call, replayed on base after the lambda fields
+type slogOp struct {
+	group string
+	attrs []slog.Attr
+}
+
+func (o slogOp) apply(h slog.Handler) slog.Handler {
+	if o.group != "" {
+		return h.WithGroup(o.group)
+	}
+	return h.WithAttrs(o.attrs)
+}
+
+// NewSlogHandler add the lambda fields of lc and write to next, e.g. slog.NewJSONHandler
+func NewSlogHandler(next slog.Handler, lc *LambdaLogContext) *SlogHandler {
+	if err := lc.Validate(); err != nil && next.Enabled(context.Background(), slog.LevelWarn) {
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, collisionMessage, 0)
+		r.AddAttrs(slog.String("error", err.Error()))
+		next.Handle(context.Background(), r)
+	}
+	return &SlogHandler{base: next, next: next, lc: lc}
+}
+
+// Enabled reports whether next handles level
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle the record with the lambda fields of ctx
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf [16]zapcore.Field
+	fields := h.lc.AppendContextValues(buf[:0], ctx)
+	if len(fields) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+	if len(h.ops) == 0 {
+		// no groups, the fields can go on the record
+		r = r.Clone()
+		for _, f := range fields {
+			r.AddAttrs(slogAttr(f))
+		}
+		return h.next.Handle(ctx, r)
+	}
+	if c := h.cache.Load(); c != nil && sameFields(c.fields, fields) {
+		return c.handler.Handle(ctx, r)
+	}
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slogAttr(f)
+	}
+	next := h.base.WithAttrs(attrs)
+	for _, o := range h.ops {
+		next = o.apply(next)
+	}
+	h.cache.Store(&slogCache{fields: append([]zapcore.Field(nil), fields...), handler: next})
+	return next.Handle(ctx, r)
+}
+
+func (h *SlogHandler) with(o slogOp) *SlogHandler {
+	ops := make([]slogOp, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &SlogHandler{base: h.base, next: o.apply(h.next), ops: append(ops, o), lc: h.lc}
+}
+
+// WithAttrs a new SlogHandler with next.WithAttrs(attrs)
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(slogOp{attrs: attrs})
+}
+
+// WithGroup a new SlogHandler with next.WithGroup(name)
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(slogOp{group: name})
+}
+
+// NewZapSlogHandler a SlogHandler which writes through core, so slog and zap loggers sharing core
+// produce identical lambda fields
+func NewZapSlogHandler(core zapcore.Core, lc *LambdaLogContext) *SlogHandler {
+	return NewSlogHandler(&zapSlogHandler{core: core}, lc)
+}
+
+type zapSlogHandler struct {
+	core zapcore.Core
+}
+
+func (h *zapSlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.core.Enabled(zapLevel(level))
+}
+
+func (h *zapSlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ent := zapcore.Entry{Level: zapLevel(r.Level), Time: r.Time, Message: r.Message}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ent.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	ce := h.core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	fields := make([]zapcore.Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogFields(fields, a)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *zapSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &zapSlogHandler{core: h.core.With(appendSlogFields(nil, attrs...))}
+}
+
+func (h *zapSlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &zapSlogHandler{core: h.core.With([]zapcore.Field{zap.Namespace(name)})}
+}
+
+// zapLevel the zap level of a slog level, levels between slog's levels round down
+func zapLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case l < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case l < slog.LevelError:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// appendSlogFields converts attrs following the slog.Handler rules: empty attrs are ignored
+// and groups without a key are inlined
+func appendSlogFields(fields []zapcore.Field, attrs ...slog.Attr) []zapcore.Field {
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		if a.Value.Kind() == slog.KindGroup && a.Key == "" {
+			fields = appendSlogFields(fields, a.Value.Group()...)
+			continue
+		}
+		fields = append(fields, slogField(a))
+	}
+	return fields
+}
+
+func slogField(a slog.Attr) zapcore.Field {
+	v := a.Value
+	switch v.Kind() {
+	case slog.KindString:
+		return zap.String(a.Key, v.String())
+	case slog.KindInt64:
+		return zap.Int64(a.Key, v.Int64())
+	case slog.KindUint64:
+		return zap.Uint64(a.Key, v.Uint64())
+	case slog.KindFloat64:
+		return zap.Float64(a.Key, v.Float64())
+	case slog.KindBool:
+		return zap.Bool(a.Key, v.Bool())
+	case slog.KindDuration:
+		return zap.Duration(a.Key, v.Duration())
+	case slog.KindTime:
+		return zap.Time(a.Key, v.Time())
+	case slog.KindGroup:
+		return zap.Object(a.Key, slogGroup(v.Group()))
+	}
+	if err, ok := v.Any().(error); ok {
+		return zap.NamedError(a.Key, err)
+	}
+	return zap.Any(a.Key, v.Any())
+}
+
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range appendSlogFields(nil, g...) {
+		f.AddTo(enc)
+	}
+	return nil
+}
+
+// slogAttr the slog attr of a lambda field
+func slogAttr(f zapcore.Field) slog.Attr {
+	switch f.Type {
+	case zapcore.StringType:
+		return slog.String(f.Key, f.String)
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return slog.Int64(f.Key, f.Integer)
+	case zapcore.BoolType:
+		return slog.Bool(f.Key, f.Integer == 1)
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return slog.Any(f.Key, enc.Fields[f.Key])
+}
@@ -0,0 +1,135 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSlogHandler(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	ctx, cf := getContext()
+	defer cf()
+	lf := New().WithBasic().With(MemoryLimitInMB).WithEnv("SHELL").WithCustom("custom1")
+
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(ctx)...)
+
+	buf := &bytes.Buffer{}
+	slog.New(NewSlogHandler(slog.NewJSONHandler(buf, nil), lf)).InfoContext(ctx, "test")
+	var value map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &value))
+	for k, v := range tw.value {
+		assert.Equal(t, v, value[k], k)
+	}
+	assert.Equal(t, "dummyid", value["requestId"])
+
+	buf.Reset()
+	slog.New(NewSlogHandler(slog.NewJSONHandler(buf, nil), lf)).Info("no context")
+	assert.NotContains(t, buf.String(), "requestId")
+
+	buf.Reset()
+	slog.New(NewSlogHandler(slog.NewJSONHandler(buf, nil), lf)).WithGroup("g").With("a", 1).WithGroup("h").InfoContext(ctx, "grouped", "b", 2)
+	value = make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &value))
+	assert.Equal(t, "dummyid", value["requestId"])
+	assert.Equal(t, map[string]interface{}{"a": float64(1), "h": map[string]interface{}{"b": float64(2)}}, value["g"])
+}
+
+func TestSlogHandlerConcurrent(t *testing.T) {
+	lf := New().With(AwsRequestID).WithCustom("custom1")
+	buf := &bytes.Buffer{}
+	plain := slog.New(NewSlogHandler(slog.NewJSONHandler(buf, nil), lf))
+	for _, logger := range []*slog.Logger{plain, plain.With("a", 1).WithGroup("g")} {
+		buf.Reset()
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: id})
+				for j := 0; j < 50; j++ {
+					logger.InfoContext(ctx, id)
+				}
+			}(strconv.Itoa(i))
+		}
+		wg.Wait()
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 1000)
+		for _, line := range lines {
+			var value map[string]interface{}
+			assert.Nil(t, json.Unmarshal([]byte(line), &value))
+			assert.Equal(t, value["msg"], value["requestId"])
+		}
+	}
+}
+
+func TestZapSlogHandler(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	ctx, cf := getContext()
+	defer cf()
+	lf := New().With(AwsRequestID, MemoryLimitInMB).WithCustom("custom1")
+
+	buf := &bytes.Buffer{}
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	core := zapcore.NewCore(enc, zapcore.AddSync(buf), zapcore.InfoLevel)
+	logger := slog.New(NewZapSlogHandler(core, lf)).With("a", 1).WithGroup("g")
+	logger.DebugContext(ctx, "not logged")
+	logger.WarnContext(ctx, "test", "d", time.Second, "err", errors.New("boom"),
+		slog.Group("", slog.Bool("inline", true)), slog.Group("sub", "s", "v"))
+
+	var value map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &value))
+	assert.Equal(t, "warn", value["level"])
+	assert.Equal(t, "test", value["msg"])
+	assert.Contains(t, value["caller"], "slog_test.go")
+	assert.Equal(t, float64(1), value["a"])
+	assert.Equal(t, "dummyid", value["requestId"])
+	assert.Equal(t, float64(128), value["memoryLimitInMB"])
+	assert.Equal(t, "dummycustom1", value["custom1"])
+	g := value["g"].(map[string]interface{})
+	assert.NotContains(t, g, "requestId")
+	assert.Equal(t, float64(1), g["d"])
+	assert.Equal(t, "boom", g["err"])
+	assert.Equal(t, true, g["inline"])
+	assert.Equal(t, map[string]interface{}{"s": "v"}, g["sub"])
+}
+
+func TestZapLevel(t *testing.T) {
+	assert.Equal(t, zapcore.DebugLevel, zapLevel(slog.LevelDebug-1))
+	assert.Equal(t, zapcore.InfoLevel, zapLevel(slog.LevelInfo+1))
+	assert.Equal(t, zapcore.WarnLevel, zapLevel(slog.LevelWarn))
+	assert.Equal(t, zapcore.ErrorLevel, zapLevel(slog.LevelError+4))
+}
@@ -0,0 +1,116 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Keys of the fields added by Go
+const (
+	TaskIDKey       = "taskId"
+	ParentTaskIDKey = "parentTaskId"
+)
+
+type taskGroupKey struct{}
+type taskIDKey struct{}
+
+// taskGroup the goroutines started with Go during an invocation
+type taskGroup struct {
+	sync.Mutex
+	next    int
+	running map[int]time.Time
+	ended   bool
+}
+
+func withTaskGroup(ctx context.Context) (context.Context, *taskGroup) {
+	tg := &taskGroup{running: make(map[int]time.Time)}
+	return context.WithValue(ctx, taskGroupKey{}, tg), tg
+}
+
+// Go run fn on a new goroutine with a context logging with ctx's logger and a taskId.
+// Goroutines still running when the Middleware's invocation ends are logged, they may leak into the next invocation.
+// A goroutine is running until fn has returned and its deferred calls have run, so one which signals completion
+// from inside fn (e.g. wg.Done) may still be logged when the invocation ends right after
+func Go(ctx context.Context, fn func(ctx context.Context)) {
+	tg, ok := ctx.Value(taskGroupKey{}).(*taskGroup)
+	if !ok {
+		go fn(ctx)
+		return
+	}
+	id := tg.start()
+	fields := []zap.Field{zap.String(TaskIDKey, id)}
+	if parent := TaskID(ctx); parent != "" {
+		fields = append(fields, zap.String(ParentTaskIDKey, parent))
+	}
+	child := context.WithValue(WithFields(ctx, fields...), taskIDKey{}, id)
+	go func() {
+		defer tg.done(child, id)
+		fn(child)
+	}()
+}
+
+// TaskID of the goroutine started with Go, empty otherwise
+func TaskID(ctx context.Context) string {
+	id, _ := ctx.Value(taskIDKey{}).(string)
+	return id
+}
+
+func (tg *taskGroup) start() string {
+	tg.Lock()
+	defer tg.Unlock()
+	tg.next++
+	tg.running[tg.next] = time.Now()
+	return strconv.Itoa(tg.next)
+}
+
+// done log a goroutine finishing after its invocation ended
+func (tg *taskGroup) done(ctx context.Context, id string) {
+	n, _ := strconv.Atoi(id)
+	tg.Lock()
+	started := tg.running[n]
+	delete(tg.running, n)
+	ended := tg.ended
+	tg.Unlock()
+	if ended {
+		Logger(ctx).Warn("leaked goroutine finished", zap.Duration("running", time.Since(started)))
+	}
+}
+
+// end log the goroutines still running, ctx has the invocation's logger
+func (tg *taskGroup) end(ctx context.Context) {
+	tg.Lock()
+	tg.ended = true
+	ids := make([]int, 0, len(tg.running))
+	for n := range tg.running {
+		ids = append(ids, n)
+	}
+	sort.Ints(ids)
+	started := make([]time.Time, len(ids))
+	for i, n := range ids {
+		started[i] = tg.running[n]
+	}
+	tg.Unlock()
+	for i, n := range ids {
+		Logger(ctx).Warn("goroutine outlived invocation",
+			zap.String(TaskIDKey, strconv.Itoa(n)), zap.Duration("running", time.Since(started[i])))
+	}
+}
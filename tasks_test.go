@@ -0,0 +1,91 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestGo(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	core, logs := observer.New(zap.InfoLevel)
+	release := make(chan struct{})
+	h := NewMiddleware(zap.New(core), New().With(AwsRequestID)).WrapFunc(func(ctx context.Context) error {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		Go(ctx, func(ctx context.Context) {
+			defer wg.Done()
+			Info(ctx, "parent")
+			Go(ctx, func(ctx context.Context) {
+				defer wg.Done()
+				Info(ctx, "child")
+			})
+		})
+		wg.Wait()
+		// fn returning does not deregister the task yet, wait for it
+		waitTasks(ctx.Value(taskGroupKey{}).(*taskGroup), 0)
+		Go(ctx, func(ctx context.Context) {
+			<-release
+		})
+		return nil
+	})
+	_, err := h.Invoke(lc, []byte("{}"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "1", logs.FilterMessage("parent").All()[0].ContextMap()[TaskIDKey])
+	child := logs.FilterMessage("child").All()[0].ContextMap()
+	assert.Equal(t, "2", child[TaskIDKey])
+	assert.Equal(t, "1", child[ParentTaskIDKey])
+	assert.Equal(t, "dummyid", child["requestId"])
+	leaked := logs.FilterMessage("goroutine outlived invocation").All()
+	if assert.Len(t, leaked, 1) {
+		assert.Equal(t, "3", leaked[0].ContextMap()[TaskIDKey])
+		assert.Equal(t, "dummyid", leaked[0].ContextMap()["requestId"])
+	}
+
+	close(release)
+	for i := 0; i < 100 && logs.FilterMessage("leaked goroutine finished").Len() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, "3", logs.FilterMessage("leaked goroutine finished").All()[0].ContextMap()[TaskIDKey])
+}
+
+func waitTasks(tg *taskGroup, n int) {
+	for {
+		tg.Lock()
+		running := len(tg.running)
+		tg.Unlock()
+		if running == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGoWithoutMiddleware(t *testing.T) {
+	done := make(chan string)
+	Go(context.Background(), func(ctx context.Context) {
+		done <- TaskID(ctx)
+	})
+	assert.Equal(t, "", <-done)
+}
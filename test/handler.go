@@ -26,15 +26,18 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// Create a new lambda log context and use RequestID... and a variable from environment
-var lambdazapper = lambdazap.New().
-	With(lambdazap.AwsRequestID, lambdazap.FunctionName, lambdazap.InvokeFunctionArn).
-	WithEnv("ZAP_TEST")
+var lambdazapper *lambdazap.LambdaLogContext
 var logger *zap.Logger
 
 func init() {
-	// Init the logger outside of the handler
-	logger = getLogger()
+	// Init the logger outside of the handler, use RequestID... and a variable from environment
+	var err error
+	logger, lambdazapper, err = lambdazap.NewProduction(
+		lambdazap.FromConfig(lambdazap.Config{Fields: []string{"requestId", "functionName", "arn"}, Env: []string{"ZAP_TEST"}}),
+		lambdazap.Output(zapcore.AddSync(writer)))
+	if err != nil {
+		panic(err)
+	}
 }
 
 type lambdaWrtier struct {
@@ -49,12 +52,6 @@ func (w *lambdaWrtier) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-func getLogger() *zap.Logger {
-	en := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
-	core := zapcore.NewCore(en, zapcore.AddSync(writer), zap.InfoLevel)
-	return zap.New(core)
-}
-
 // Handler for lambda
 func Handler(ctx context.Context) (map[string]interface{}, error) {
 	// defer logger.Sync()
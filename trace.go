@@ -0,0 +1,247 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Trace headers
+const (
+	XRayTraceHeader   = "X-Amzn-Trace-Id"
+	TraceparentHeader = "traceparent"
+)
+
+// xrayContextKey the context key aws-lambda-go stores the invocation's trace header with
+const xrayContextKey = "x-amzn-trace-id"
+
+// xrayTraceEnv the trace header of the current invocation, set by the lambda runtime
+const xrayTraceEnv = "_X_AMZN_TRACE_ID"
+
+// XRayTraceHeaderValue of the invocation, e.g. Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1
+func XRayTraceHeaderValue(ctx context.Context) string {
+	if v, ok := ctx.Value(xrayContextKey).(string); ok && v != "" {
+		return v
+	}
+	return os.Getenv(xrayTraceEnv)
+}
+
+// xrayTrace the parts of an X-Ray trace header
+type xrayTrace struct {
+	root    string
+	parent  string
+	sampled bool
+}
+
+func parseXRay(header string) (xrayTrace, bool) {
+	var t xrayTrace
+	for _, part := range strings.Split(header, ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "Root":
+			t.root = kv[1]
+		case "Parent":
+			t.parent = kv[1]
+		case "Sampled":
+			t.sampled = kv[1] == "1"
+		}
+	}
+	return t, t.root != ""
+}
+
+// traceID the 32 hex W3C trace id of the root, 1-5759e988-bd862e3fe1be46a994272793 is 5759e988bd862e3fe1be46a994272793
+func (t xrayTrace) traceID() (string, bool) {
+	parts := strings.Split(t.root, "-")
+	if len(parts) != 3 || parts[0] != "1" || len(parts[1]) != 8 || len(parts[2]) != 24 {
+		return "", false
+	}
+	id := parts[1] + parts[2]
+	return id, isHex(id)
+}
+
+// SpanContext the W3C trace context of a span
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	TraceFlags byte
+}
+
+// IsValid when both ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// IsSampled when the sampled flag is set
+func (sc SpanContext) IsSampled() bool {
+	return sc.TraceFlags&1 == 1
+}
+
+// TraceIDString the 32 hex trace id
+func (sc SpanContext) TraceIDString() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+// SpanIDString the 16 hex span id
+func (sc SpanContext) SpanIDString() string {
+	return hex.EncodeToString(sc.SpanID[:])
+}
+
+// TraceFlagsString the 2 hex trace flags
+func (sc SpanContext) TraceFlagsString() string {
+	return hex.EncodeToString([]byte{sc.TraceFlags})
+}
+
+// decodeHex s into b, false unless s is exactly len(b) bytes of lowercase hex
+func decodeHex(b []byte, s string) bool {
+	if len(s) != 2*len(b) || !isHex(s) {
+		return false
+	}
+	_, err := hex.Decode(b, []byte(s))
+	return err == nil
+}
+
+// xraySpanContext of an X-Ray trace header
+func xraySpanContext(header string) (SpanContext, bool) {
+	var sc SpanContext
+	t, ok := parseXRay(header)
+	if !ok {
+		return sc, false
+	}
+	id, ok := t.traceID()
+	if !ok || !decodeHex(sc.TraceID[:], id) || !decodeHex(sc.SpanID[:], t.parent) {
+		return sc, false
+	}
+	if t.sampled {
+		sc.TraceFlags = 1
+	}
+	return sc, sc.IsValid()
+}
+
+// xrayHeader the X-Ray trace header of a span context
+func xrayHeader(sc SpanContext) string {
+	id := sc.TraceIDString()
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+	return "Root=1-" + id[:8] + "-" + id[8:] + ";Parent=" + sc.SpanIDString() + ";Sampled=" + sampled
+}
+
+// parseTraceparent a W3C traceparent header, e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func parseTraceparent(tp string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(tp), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isHex(parts[0]) || (parts[0] == "00" && len(parts) != 4) {
+		return sc, false
+	}
+	var flags [1]byte
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return sc, false
+	}
+	sc.TraceFlags = flags[0]
+	return sc, sc.IsValid()
+}
+
+// formatTraceparent the W3C traceparent header of sc
+func formatTraceparent(sc SpanContext) string {
+	return "00-" + sc.TraceIDString() + "-" + sc.SpanIDString() + "-" + sc.TraceFlagsString()
+}
+
+type traceparentKey struct{}
+
+// WithTraceparent a context with an inbound W3C traceparent. The Middleware adds one, see Traceparent
+func WithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceparentKey{}, traceparent)
+}
+
+// ActiveSpanFunc returns the span context of the active span of ctx, false when there is none
+type ActiveSpanFunc func(ctx context.Context) (SpanContext, bool)
+
+var activeSpan struct {
+	sync.RWMutex
+	fn ActiveSpanFunc
+}
+
+// SetActiveSpanFunc used by TraceContext to find the active span, e.g. lambdazapotel.ActiveSpan. nil removes it
+func SetActiveSpanFunc(fn ActiveSpanFunc) {
+	activeSpan.Lock()
+	defer activeSpan.Unlock()
+	activeSpan.fn = fn
+}
+
+// TraceContext of the active span of ctx (see SetActiveSpanFunc), the traceparent of WithTraceparent,
+// or the X-Ray trace header. false when there is none
+func TraceContext(ctx context.Context) (SpanContext, bool) {
+	activeSpan.RLock()
+	fn := activeSpan.fn
+	activeSpan.RUnlock()
+	if fn != nil {
+		if sc, ok := fn(ctx); ok && sc.IsValid() {
+			return sc, true
+		}
+	}
+	if tp, ok := ctx.Value(traceparentKey{}).(string); ok {
+		if sc, ok := parseTraceparent(tp); ok {
+			return sc, true
+		}
+	}
+	return xraySpanContext(XRayTraceHeaderValue(ctx))
+}
+
+// DefaultTraceparentSources traceparent headers, SQS/SNS message attributes and EventBridge detail metadata
+var DefaultTraceparentSources = []CorrelationSource{
+	HeaderSource(TraceparentHeader),
+	MessageAttributeSource(TraceparentAttribute),
+	EventBridgeSource(EventBridgeMetadataKey + "." + TraceparentAttribute),
+}
+
+func traceResolver(value func(sc SpanContext) string) Resolver {
+	return func(ctx context.Context, lc *lambdacontext.LambdaContext, key string) zapcore.Field {
+		sc, ok := TraceContext(ctx)
+		if !ok {
+			return zap.Skip()
+		}
+		return zap.String(key, value(sc))
+	}
+}
+
+// Trace fields of TraceContext, not logged when there is no trace
+var (
+	TraceID    = MustRegisterField(FieldDef{Name: "trace_id", Kind: UserKind, Resolve: traceResolver(SpanContext.TraceIDString)})
+	SpanID     = MustRegisterField(FieldDef{Name: "span_id", Kind: UserKind, Resolve: traceResolver(SpanContext.SpanIDString)})
+	TraceFlags = MustRegisterField(FieldDef{Name: "trace_flags", Kind: UserKind, Resolve: traceResolver(SpanContext.TraceFlagsString)})
+	// TraceFields trace_id, span_id and trace_flags
+	TraceFields = []LambdaField{TraceID, SpanID, TraceFlags}
+)
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestXRayConversion(t *testing.T) {
+	sc, ok := xraySpanContext("Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0")
+	assert.True(t, ok)
+	assert.Equal(t, "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-00", formatTraceparent(sc))
+	assert.Equal(t, "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=0", xrayHeader(sc))
+	_, ok = xraySpanContext("Root=1-5759e988-bd862e3fe1be46a994272793")
+	assert.False(t, ok)
+	_, ok = xraySpanContext("Root=2-xyz;Parent=53995c3f42cd8ad8")
+	assert.False(t, ok)
+
+	sc, ok = parseTraceparent(testTraceparent)
+	assert.True(t, ok)
+	assert.Equal(t, "Root=1-4bf92f35-77b34da6a3ce929d0e0e4736;Parent=00f067aa0ba902b7;Sampled=1", xrayHeader(sc))
+}
+
+func TestParseTraceparent(t *testing.T) {
+	sc, ok := parseTraceparent(testTraceparent)
+	assert.True(t, ok)
+	assert.Equal(t, testTraceparent, formatTraceparent(sc))
+	_, ok = parseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future")
+	assert.True(t, ok)
+	for _, tp := range []string{
+		"",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+	} {
+		_, ok = parseTraceparent(tp)
+		assert.False(t, ok, tp)
+	}
+}
+
+func TestXRayTraceHeaderValue(t *testing.T) {
+	os.Setenv(xrayTraceEnv, "env")
+	defer os.Unsetenv(xrayTraceEnv)
+	assert.Equal(t, "env", XRayTraceHeaderValue(context.Background()))
+	assert.Equal(t, "ctx", XRayTraceHeaderValue(context.WithValue(context.Background(), xrayContextKey, "ctx")))
+}
+
+func TestTraceFields(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), lc)
+	lf := New().With(AwsRequestID).With(TraceFields...)
+	logger, tw := getLogger()
+
+	logger.Info("none", lf.ContextValues(ctx)...)
+	assert.NotContains(t, tw.value, "trace_id")
+
+	ctx = context.WithValue(ctx, xrayContextKey, testXRay)
+	logger.Info("xray", lf.ContextValues(ctx)...)
+	assert.Equal(t, "5759e988bd862e3fe1be46a994272793", tw.value["trace_id"])
+	assert.Equal(t, "53995c3f42cd8ad8", tw.value["span_id"])
+	assert.Equal(t, "01", tw.value["trace_flags"])
+
+	ctx = WithTraceparent(ctx, testTraceparent)
+	logger.Info("traceparent", lf.ContextValues(ctx)...)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tw.value["trace_id"])
+
+	sc, _ := parseTraceparent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+	SetActiveSpanFunc(func(ctx context.Context) (SpanContext, bool) {
+		return sc, true
+	})
+	defer SetActiveSpanFunc(nil)
+	logger.Info("active", lf.ContextValues(ctx)...)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", tw.value["trace_id"])
+	assert.Equal(t, "b7ad6b7169203331", tw.value["span_id"])
+	assert.Equal(t, "00", tw.value["trace_flags"])
+}
+
+func TestMiddlewareTraceparent(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), lc)
+	core, logs := observer.New(zap.InfoLevel)
+	h := NewMiddleware(zap.New(core), New().With(TraceFields...), Traceparent()).WrapFunc(func(ctx context.Context) error {
+		Info(ctx, "hello")
+		return nil
+	})
+	_, err := h.Invoke(ctx, []byte(`{"Records": [{"messageAttributes": {"traceparent": {"stringValue": "`+testTraceparent+`"}}}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", logs.All()[0].ContextMap()["trace_id"])
+}
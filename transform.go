@@ -0,0 +1,169 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Transformer changes a value before it is logged
+type Transformer interface {
+	Transform(value string) string
+}
+
+// TransformerFunc adapts a func to a Transformer
+type TransformerFunc func(value string) string
+
+// Transform calls f(value)
+func (f TransformerFunc) Transform(value string) string {
+	return f(value)
+}
+
+type dropTransformer struct{}
+
+func (dropTransformer) Transform(value string) string {
+	return ""
+}
+
+// Drop the field, it is never logged
+var Drop Transformer = dropTransformer{}
+
+// HMAC pseudonymise with a keyed HMAC-SHA256, hex encoded.
+// The same key gives the same pseudonym across invocations so values can still be correlated.
+func HMAC(key []byte) Transformer {
+	return TransformerFunc(func(value string) string {
+		if value == "" {
+			return value
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))
+	})
+}
+
+// PartialMask keep the first prefix and last suffix characters and replace the rest with *.
+// Values too short to hide anything are masked completely
+func PartialMask(prefix, suffix int) Transformer {
+	return TransformerFunc(func(value string) string {
+		r := []rune(value)
+		if len(r) <= prefix+suffix {
+			return strings.Repeat("*", len(r))
+		}
+		return string(r[:prefix]) + strings.Repeat("*", len(r)-prefix-suffix) + string(r[len(r)-suffix:])
+	})
+}
+
+// Lowercase the value
+var Lowercase Transformer = TransformerFunc(strings.ToLower)
+
+// ArnQualifier keep only the qualifier (version or alias) of a function ARN, empty when unqualified.
+// e.g. arn:aws:lambda:us-east-1:123456789012:function:my-function:prod becomes prod
+var ArnQualifier Transformer = TransformerFunc(func(value string) string {
+	parts := strings.Split(value, ":")
+	if len(parts) != 8 {
+		return ""
+	}
+	return parts[7]
+})
+
+// TruncateBytes to at most n bytes without splitting a character
+func TruncateBytes(n int) Transformer {
+	return TransformerFunc(func(value string) string {
+		return string(truncateUTF8([]byte(value), n))
+	})
+}
+
+// DefaultValue d when the value is empty
+func DefaultValue(d string) Transformer {
+	return TransformerFunc(func(value string) string {
+		if value == "" {
+			return d
+		}
+		return value
+	})
+}
+
+// ChainTransformers apply each transformer in order
+func ChainTransformers(ts ...Transformer) Transformer {
+	return TransformerFunc(func(value string) string {
+		for _, t := range ts {
+			value = t.Transform(value)
+		}
+		return value
+	})
+}
+
+// then t runs after an existing transformer. Drop always wins
+func then(existing, t Transformer) Transformer {
+	if _, ok := existing.(dropTransformer); ok {
+		return existing
+	}
+	if _, ok := t.(dropTransformer); ok || existing == nil {
+		return t
+	}
+	return ChainTransformers(existing, t)
+}
+
+// TransformFields apply t to the values of these fields.
+// Transformers for the same field run in the order they were added
+func TransformFields(t Transformer, fields ...LambdaField) Option {
+	return optionFunc(func(lc *LambdaLogContext) {
+		if lc.transforms == nil {
+			lc.transforms = make(map[LambdaField]Transformer)
+		}
+		for _, f := range fields {
+			lc.transforms[f] = then(lc.transforms[f], t)
+		}
+	})
+}
+
+// TransformKeys apply t to the values of WithEnv and WithCustom keys
+func TransformKeys(t Transformer, keys ...string) Option {
+	return optionFunc(func(lc *LambdaLogContext) {
+		if lc.keyTransforms == nil {
+			lc.keyTransforms = make(map[string]Transformer)
+		}
+		for _, k := range keys {
+			lc.keyTransforms[k] = then(lc.keyTransforms[k], t)
+		}
+	})
+}
+
+func (lc *LambdaLogContext) transform(f LambdaField, value string) string {
+	if t, ok := lc.transforms[f]; ok {
+		return t.Transform(value)
+	}
+	return value
+}
+
+func (lc *LambdaLogContext) transformKey(key string, value string) string {
+	if t, ok := lc.keyTransforms[key]; ok {
+		return t.Transform(value)
+	}
+	return value
+}
+
+func (lc *LambdaLogContext) dropped(f LambdaField) bool {
+	_, ok := lc.transforms[f].(dropTransformer)
+	return ok
+}
+
+func (lc *LambdaLogContext) droppedKey(key string) bool {
+	_, ok := lc.keyTransforms[key].(dropTransformer)
+	return ok
+}
@@ -0,0 +1,59 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartialMask(t *testing.T) {
+	assert.Equal(t, "du******nt", PartialMask(2, 2).Transform("dummyident"))
+	assert.Equal(t, "***", PartialMask(2, 2).Transform("abc"))
+}
+
+func TestTransformers(t *testing.T) {
+	assert.Equal(t, "none", DefaultValue("none").Transform(""))
+	assert.Equal(t, "", ArnQualifier.Transform("arn:aws:lambda:us-east-1:123456789012:function:f"))
+	assert.Equal(t, "h", TruncateBytes(2).Transform("hé"))
+	assert.Equal(t, "abc", ChainTransformers(Lowercase, TruncateBytes(3)).Transform("ABCD"))
+}
+
+func TestTransformIdentity(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	lc, cf := getContext()
+	defer cf()
+	key := []byte("secret")
+	lf := New(
+		TransformFields(HMAC(key), CognitoIdentityID),
+		TransformFields(PartialMask(0, 4), InstallationID),
+		TransformFields(Drop, CognitoIdentityPoolID),
+		TransformKeys(PartialMask(1, 0), "custom1"),
+		TransformKeys(Drop, "SHELL"),
+	).WithAll().WithEnv("SHELL").WithCustom("custom1")
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Equal(t, HMAC(key).Transform("dummyident"), tw.value["cognitoIdentityId"])
+	assert.Len(t, tw.value["cognitoIdentityId"], 64)
+	assert.Equal(t, "**********llid", tw.value["installationId"])
+	assert.Equal(t, "d***********", tw.value["custom1"])
+	assert.NotContains(t, tw.value, "cognitoIdentityPoolId")
+	assert.NotContains(t, tw.value, "SHELL")
+	assert.Equal(t, "dummyid", tw.value["requestId"])
+}
@@ -0,0 +1,114 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Transport an http.RoundTripper logging outbound calls with the request scoped logger of the request's context.
+// X-Correlation-Id, X-Amzn-Trace-Id and traceparent (see TraceContext) are added to requests which don't have them
+type Transport struct {
+	next http.RoundTripper
+}
+
+// NewTransport wrap next, http.DefaultTransport when nil
+func NewTransport(next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{next: next}
+}
+
+// RoundTrip inject the headers, call next and log when the response body is closed
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	out := req.Clone(ctx)
+	setHeader(out.Header, CorrelationIDHeader, CorrelationIDFromContext(ctx))
+	xray := XRayTraceHeaderValue(ctx)
+	if sc, ok := TraceContext(ctx); ok {
+		setHeader(out.Header, TraceparentHeader, formatTraceparent(sc))
+		if xray == "" {
+			xray = xrayHeader(sc)
+		}
+	}
+	setHeader(out.Header, XRayTraceHeader, xray)
+	var reqBody *countingBody
+	if req.Body != nil && req.Body != http.NoBody {
+		reqBody = &countingBody{ReadCloser: req.Body}
+		out.Body = reqBody
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(out)
+	latency := time.Since(start)
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("host", req.URL.Host),
+		zap.String("path", req.URL.Path),
+	}
+	if err != nil {
+		Logger(ctx).Warn("http request failed", append(fields, zap.Duration("latency", latency), zap.Error(err))...)
+		return resp, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, onClose: func(n int64) {
+		var sent int64
+		if reqBody != nil {
+			sent = reqBody.n.Load()
+		}
+		Logger(ctx).Info("http request", append(fields,
+			zap.Int("status", resp.StatusCode),
+			zap.Duration("latency", latency),
+			zap.Int64("requestBytes", sent),
+			zap.Int64("responseBytes", n))...)
+	}}
+	return resp, nil
+}
+
+func setHeader(h http.Header, key, value string) {
+	if value != "" && h.Get(key) == "" {
+		h.Set(key, value)
+	}
+}
+
+// countingBody counts the bytes read and calls onClose once
+type countingBody struct {
+	io.ReadCloser
+	n       atomic.Int64
+	once    sync.Once
+	onClose func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.onClose != nil {
+		b.once.Do(func() {
+			b.onClose(b.n.Load())
+		})
+	}
+	return err
+}
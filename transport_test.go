@@ -0,0 +1,84 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const testXRay = "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"
+
+func TestTransport(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := WithLogger(lambdacontext.NewContext(context.Background(), lc), zap.New(core).With(zap.String("requestId", "dummyid")))
+	ctx = context.WithValue(ctx, xrayContextKey, testXRay)
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/items", strings.NewReader("body"))
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	assert.Equal(t, "dummyid", got.Get(CorrelationIDHeader))
+	assert.Equal(t, testXRay, got.Get(XRayTraceHeader))
+	assert.Equal(t, "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01", got.Get(TraceparentHeader))
+	assert.Empty(t, req.Header.Get(CorrelationIDHeader))
+	if assert.Equal(t, 1, logs.Len()) {
+		m := logs.All()[0].ContextMap()
+		assert.Equal(t, "dummyid", m["requestId"])
+		assert.Equal(t, "POST", m["method"])
+		assert.Equal(t, strings.TrimPrefix(srv.URL, "http://"), m["host"])
+		assert.Equal(t, "/items", m["path"])
+		assert.Equal(t, int64(201), m["status"])
+		assert.Equal(t, int64(4), m["requestBytes"])
+		assert.Equal(t, int64(5), m["responseBytes"])
+		assert.Contains(t, m, "latency")
+	}
+
+	req, _ = http.NewRequestWithContext(WithCorrelationID(ctx, "inherited"), http.MethodGet, srv.URL, nil)
+	req.Header.Set(TraceparentHeader, "mine")
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "inherited", got.Get(CorrelationIDHeader))
+	assert.Equal(t, "mine", got.Get(TraceparentHeader))
+
+	srv.Close()
+	_, err = client.Do(req)
+	assert.Error(t, err)
+	assert.Equal(t, 1, logs.FilterMessage("http request failed").Len())
+	assert.Equal(t, zapcore.WarnLevel, logs.FilterMessage("http request failed").All()[0].Level)
+}
@@ -0,0 +1,105 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ValueType of a WithTypedCustom key
+type ValueType int
+
+// Value types. A value which does not parse is logged as a string
+const (
+	StringValue ValueType = iota
+	IntValue
+	BoolValue
+	FloatValue
+	// DurationValue parsed with time.ParseDuration
+	DurationValue
+)
+
+// TypedContextValuer like ContextValuer but returns a field of any type. key is the name of the field.
+// Transformers are applied to string fields.
+type TypedContextValuer interface {
+	TypedContextValue(ctx *lambdacontext.LambdaContext, f LambdaField, key string) (zapcore.Field, error)
+}
+
+// TypedContextValuerFunc adapts a func to a TypedContextValuer
+type TypedContextValuerFunc func(ctx *lambdacontext.LambdaContext, f LambdaField, key string) (zapcore.Field, error)
+
+// TypedContextValue calls fn(ctx, f, key)
+func (fn TypedContextValuerFunc) TypedContextValue(ctx *lambdacontext.LambdaContext, f LambdaField, key string) (zapcore.Field, error) {
+	return fn(ctx, f, key)
+}
+
+// CustomTypedValues override context values with typed fields. if you return an error the string ContextValue will be used.
+func CustomTypedValues(t TypedContextValuer) Option {
+	return optionFunc(func(lc *LambdaLogContext) {
+		lc.typedBuilder = t
+	})
+}
+
+// WithTypedCustom Add names from lambdacontext.ClientContext.Custom parsed as t
+func (lc *LambdaLogContext) WithTypedCustom(t ValueType, names ...string) *LambdaLogContext {
+	if lc.customTypes == nil {
+		lc.customTypes = make(map[string]ValueType)
+	}
+	for _, n := range names {
+		lc.customTypes[n] = t
+	}
+	return lc.WithCustom(names...)
+}
+
+func (lc *LambdaLogContext) contextField(ctx *lambdacontext.LambdaContext, f LambdaField, key string) zapcore.Field {
+	if lc.typedBuilder != nil {
+		if field, err := lc.typedBuilder.TypedContextValue(ctx, f, key); err == nil {
+			if field.Type == zapcore.StringType {
+				field.String = lc.transform(f, field.String)
+			}
+			return field
+		}
+	}
+	return zap.String(key, lc.ContextValue(ctx, f))
+}
+
+// customField name is the WithCustom name, key the logged key
+func (lc *LambdaLogContext) customField(name, key, value string) zapcore.Field {
+	value = lc.transformKey(name, value)
+	switch lc.customTypes[name] {
+	case IntValue:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return zap.Int64(key, i)
+		}
+	case BoolValue:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return zap.Bool(key, b)
+		}
+	case FloatValue:
+		if fl, err := strconv.ParseFloat(value, 64); err == nil {
+			return zap.Float64(key, fl)
+		}
+	case DurationValue:
+		if d, err := time.ParseDuration(value); err == nil {
+			return zap.Duration(key, d)
+		}
+	}
+	return zap.String(key, value)
+}
@@ -0,0 +1,80 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTypedCustom(t *testing.T) {
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID: "dummyid",
+		ClientContext: lambdacontext.ClientContext{Custom: map[string]string{
+			"count":   "42",
+			"enabled": "true",
+			"ratio":   "0.5",
+			"wait":    "1s",
+			"broken":  "notanumber",
+		}},
+	})
+	lf := New().With(AwsRequestID).
+		WithTypedCustom(IntValue, "count", "broken").
+		WithTypedCustom(BoolValue, "enabled").
+		WithTypedCustom(FloatValue, "ratio").
+		WithTypedCustom(DurationValue, "wait")
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(ctx)...)
+	assert.Equal(t, float64(42), tw.value["count"])
+	assert.Equal(t, true, tw.value["enabled"])
+	assert.Equal(t, 0.5, tw.value["ratio"])
+	assert.Equal(t, float64(1), tw.value["wait"])
+	assert.Equal(t, "notanumber", tw.value["broken"])
+}
+
+func TestTypedValuer(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	typed := TypedContextValuerFunc(func(ctx *lambdacontext.LambdaContext, f LambdaField, key string) (zapcore.Field, error) {
+		if f == AppVersionCode {
+			return zap.Int(key, 7), nil
+		}
+		return zapcore.Field{}, fmt.Errorf("use default")
+	})
+	lf := New(CustomTypedValues(typed)).With(AwsRequestID, AppVersionCode)
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Equal(t, float64(7), tw.value["appVersionCode"])
+	assert.Equal(t, "dummyid", tw.value["requestId"])
+}
+
+func TestTypedValuerTransform(t *testing.T) {
+	lc, cf := getContext()
+	defer cf()
+	typed := TypedContextValuerFunc(func(ctx *lambdacontext.LambdaContext, f LambdaField, key string) (zapcore.Field, error) {
+		return zap.String(key, ctx.Identity.CognitoIdentityID), nil
+	})
+	key := []byte("secret")
+	lf := New(TransformFields(HMAC(key), CognitoIdentityID), CustomTypedValues(typed)).With(CognitoIdentityID)
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Equal(t, HMAC(key).Transform("dummyident"), tw.value["cognitoIdentityId"])
+}
@@ -0,0 +1,91 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"errors"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// ErrNotHandled no valuer in a chain returned a value for the field
+var ErrNotHandled = errors.New("lambdazap: field not handled")
+
+// ContextValuerFunc adapts a func to a ContextValuer
+type ContextValuerFunc func(ctx *lambdacontext.LambdaContext, f LambdaField) (string, error)
+
+// ContextValue calls fn(ctx, f)
+func (fn ContextValuerFunc) ContextValue(ctx *lambdacontext.LambdaContext, f LambdaField) (string, error) {
+	return fn(ctx, f)
+}
+
+// FieldValuer a ContextValuer which declares the fields it handles
+type FieldValuer interface {
+	ContextValuer
+	Handles(f LambdaField) bool
+}
+
+type fieldValuer struct {
+	ContextValuer
+	fields map[LambdaField]bool
+}
+
+func (v *fieldValuer) Handles(f LambdaField) bool {
+	return v.fields[f]
+}
+
+// ForFields restrict v to these fields
+func ForFields(v ContextValuer, fields ...LambdaField) FieldValuer {
+	fv := &fieldValuer{ContextValuer: v, fields: make(map[LambdaField]bool)}
+	for _, f := range fields {
+		fv.fields[f] = true
+	}
+	return fv
+}
+
+func handles(v ContextValuer, f LambdaField) bool {
+	if fv, ok := v.(FieldValuer); ok {
+		return fv.Handles(f)
+	}
+	return true
+}
+
+type valuerChain []ContextValuer
+
+func (c valuerChain) ContextValue(ctx *lambdacontext.LambdaContext, f LambdaField) (string, error) {
+	for _, v := range c {
+		if !handles(v, f) {
+			continue
+		}
+		if value, err := v.ContextValue(ctx, f); err == nil {
+			return value, nil
+		}
+	}
+	return "", ErrNotHandled
+}
+
+// ChainValuers ask each valuer in priority order. The first which handles the field without an error wins,
+// ErrNotHandled when none does
+func ChainValuers(valuers ...ContextValuer) ContextValuer {
+	chain := make(valuerChain, 0, len(valuers))
+	for _, v := range valuers {
+		if c, ok := v.(valuerChain); ok {
+			chain = append(chain, c...)
+		} else {
+			chain = append(chain, v)
+		}
+	}
+	return chain
+}
@@ -0,0 +1,57 @@
+// Copyright © 2018 Douglas Chimento <dchimento@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lambdazap
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+)
+
+func constValuer(value string) ContextValuer {
+	return ContextValuerFunc(func(ctx *lambdacontext.LambdaContext, f LambdaField) (string, error) {
+		return value, nil
+	})
+}
+
+func TestValuerChain(t *testing.T) {
+	defer func() {
+		reset()
+	}()
+	setStatics()
+	lc, cf := getContext()
+	defer cf()
+	lf := New(
+		CustomValues(&custValuer{}, ForFields(constValuer("ident"), CognitoIdentityID)),
+		CustomValues(ForFields(constValuer("arn:aws:lambda:us-east-1:123456789012:function:f:PROD"), InvokeFunctionArn)),
+		TransformFields(ArnQualifier, InvokeFunctionArn),
+		TransformFields(Lowercase, InvokeFunctionArn),
+		TransformFields(TruncateBytes(3), CognitoIdentityID),
+		TransformFields(DefaultValue("none"), AppTitle),
+	).With(AwsRequestID, CognitoIdentityID, InvokeFunctionArn, AppTitle, CognitoIdentityPoolID)
+	logger, tw := getLogger()
+	logger.Info("test", lf.ContextValues(lc)...)
+	assert.Equal(t, "customId", tw.value["requestId"])
+	assert.Equal(t, "ide", tw.value["cognitoIdentityId"])
+	assert.Equal(t, "prod", tw.value["arn"])
+	assert.Equal(t, "dummytitle", tw.value["appTitle"])
+	assert.Equal(t, "dummypool", tw.value["cognitoIdentityPoolId"])
+}
+
+func TestChainNotHandled(t *testing.T) {
+	_, err := ChainValuers(ForFields(constValuer("x"), AppTitle)).ContextValue(lc, AwsRequestID)
+	assert.Equal(t, ErrNotHandled, err)
+}